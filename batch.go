@@ -0,0 +1,147 @@
+package radcache
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 批量获取多个key的值，返回以原始key(不含前缀)为键的map，不存在的key不会出现在结果中
+func (rad *RadCache) MGet(keys ...string) (map[string]interface{}, error) {
+	if len(keys) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	var prefixed []string
+	for _, v := range keys {
+		prefixed = append(prefixed, rad.Key(v))
+	}
+	values, err := rad.mget(prefixed)
+	if err != nil {
+		rad.report("MGet", strings.Join(keys, ","), err)
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(keys))
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		val, err := rad.UnMarshal(str)
+		if errors.Is(err, ErrCacheMiss) {
+			continue
+		}
+		if err != nil {
+			rad.report("MGet", strings.Join(keys, ","), err)
+			return nil, err
+		}
+		result[keys[i]] = val
+	}
+	return result, nil
+}
+
+// 批量设置多个值，每个key都会带上相同的过期时间，所有SET命令通过一个pipeline发送
+func (rad *RadCache) MSet(pairs map[string]interface{}, exp time.Duration) error {
+	return rad.mset("MSet", pairs, exp)
+}
+
+// 在服务启动时预热缓存，调用source获取数据后通过一个pipeline写入，配置了TTLJitter时每个key单独计算抖动
+// source出错时不会写入任何数据，直接返回该错误
+func (rad *RadCache) WarmUp(exp time.Duration, source func() (map[string]interface{}, error)) error {
+	pairs, err := source()
+	if err != nil {
+		return err
+	}
+	return rad.mset("WarmUp", pairs, exp)
+}
+
+// MSet和WarmUp的实现，op用于错误上报
+func (rad *RadCache) mset(op string, pairs map[string]interface{}, exp time.Duration) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(pairs))
+	for k, v := range pairs {
+		val, err := rad.Marshal(v)
+		if err != nil {
+			rad.report(op, "", err)
+			return err
+		}
+		values[k] = val
+	}
+	for k := range values {
+		rad.flushPending(rad.Key(k))
+	}
+	_, err := rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		for k, v := range values {
+			pipe.Set(rad.ctx(), rad.Key(k), v, rad.expiration(exp))
+		}
+		return nil
+	})
+	for k := range values {
+		rad.invalidate(rad.Key(k))
+	}
+	if err != nil {
+		rad.report(op, "", err)
+	}
+	return err
+}
+
+// 批量获取key(已包含前缀)的值，与fetch一样优先返回写缓冲和本地缓存中的值，其余的key通过一次MGET获取，不存在的key对应nil
+func (rad *RadCache) mget(keys []string) ([]interface{}, error) {
+	values := make([]interface{}, len(keys))
+	var missing []string
+	var index []int
+	for i, k := range keys {
+		if val, ok := rad.wb.get(k); ok {
+			values[i] = val
+			continue
+		}
+		if rad.local != nil {
+			if val, ok := rad.local.get(k); ok {
+				values[i] = val
+				continue
+			}
+		}
+		missing = append(missing, k)
+		index = append(index, i)
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+	fetched, err := rad.mgetRedis(missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, v := range fetched {
+		values[index[j]] = v
+		if str, ok := v.(string); ok && rad.local != nil {
+			rad.local.set(missing[j], str)
+		}
+	}
+	return values, nil
+}
+
+// 执行MGET，集群中的多个key可能不在同一个slot，改为通过pipeline逐个GET，不存在的key对应nil
+func (rad *RadCache) mgetRedis(keys []string) ([]interface{}, error) {
+	if !rad.isCluster() {
+		return rad.db().MGet(rad.ctx(), keys...).Result()
+	}
+	cmds, err := rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		for _, k := range keys {
+			pipe.Get(rad.ctx(), k)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(cmds))
+	for i, cmd := range cmds {
+		if val, err := cmd.(*redis.StringCmd).Result(); err == nil {
+			values[i] = val
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,32 @@
+package radcache
+
+// 设置位图中offset处的位，value为0或1
+func (rad *RadCache) SetBit(key string, offset int64, value int) error {
+	rad.flushPending(rad.Key(key))
+	err := rad.db().SetBit(rad.ctx(), rad.Key(key), offset, value).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetBit", key, err)
+	}
+	return err
+}
+
+// 获取位图中offset处的位
+func (rad *RadCache) GetBit(key string, offset int64) (int64, error) {
+	result, err := rad.db().GetBit(rad.ctx(), rad.Key(key), offset).Result()
+	if err != nil {
+		rad.report("GetBit", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 统计位图中值为1的位的数量
+func (rad *RadCache) BitCount(key string) (int64, error) {
+	result, err := rad.db().BitCount(rad.ctx(), rad.Key(key), nil).Result()
+	if err != nil {
+		rad.report("BitCount", key, err)
+		return 0, err
+	}
+	return result, nil
+}
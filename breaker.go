@@ -0,0 +1,76 @@
+package radcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 熔断器打开期间所有命令直接返回该错误，不会访问redis
+var ErrCircuitOpen = errors.New("radcache: circuit breaker is open")
+
+// 未配置BreakerCooldown时熔断持续的时间
+const defaultBreakerCooldown = 5 * time.Second
+
+// 熔断器的状态，连续失败达到阈值后打开，冷却时间过后放行一个试探请求，成功则关闭，失败则继续熔断
+// 阈值等配置每次调用时从Options传入，状态由同一实例的WithContext、Clone等拷贝共享
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	firstFail time.Time
+	openUntil time.Time
+	// 是否有试探请求正在执行
+	trial bool
+}
+
+// 只有网络错误和超时才算作失败，redis.Nil和redis返回的错误不影响熔断
+func isBreakerFailure(err error) bool {
+	return isRetryable(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return ErrCircuitOpen
+	}
+	b.trial = true
+	return nil
+}
+
+func (b *circuitBreaker) report(err error, threshold int, window, cooldown time.Duration) {
+	// 被熔断拒绝的命令也会调用AfterProcess，不能计入结果
+	if errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+	failed := isBreakerFailure(err)
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.trial {
+		b.trial = false
+		if failed {
+			b.openUntil = now.Add(cooldown)
+			return
+		}
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	if b.failures == 0 || (window > 0 && now.Sub(b.firstFail) > window) {
+		b.failures = 0
+		b.firstFail = now
+	}
+	b.failures++
+	if b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+	}
+}
@@ -0,0 +1,79 @@
+package radcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const (
+		threshold = 2
+		window    = time.Minute
+		cooldown  = time.Minute
+	)
+	type step struct {
+		// report: 上报err；allow: 期望allow返回err；cool: 冷却时间结束；age: 第一次失败超出window
+		op  string
+		err error
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"opens after threshold", []step{
+			{"allow", nil}, {"report", io.EOF},
+			{"allow", nil}, {"report", io.EOF},
+			{"allow", ErrCircuitOpen},
+		}},
+		{"success resets failures", []step{
+			{"report", io.EOF}, {"report", nil}, {"report", io.EOF},
+			{"allow", nil},
+		}},
+		{"timeouts count as failures", []step{
+			{"report", context.DeadlineExceeded}, {"report", context.DeadlineExceeded},
+			{"allow", ErrCircuitOpen},
+		}},
+		{"misses and rejections are ignored", []step{
+			{"report", redis.Nil}, {"report", ErrCircuitOpen}, {"report", redis.Nil},
+			{"allow", nil},
+		}},
+		{"window restarts counting", []step{
+			{"report", io.EOF}, {"age", nil}, {"report", io.EOF},
+			{"allow", nil},
+		}},
+		{"successful trial closes", []step{
+			{"report", io.EOF}, {"report", io.EOF}, {"cool", nil},
+			{"allow", nil}, {"allow", ErrCircuitOpen}, {"report", nil},
+			{"allow", nil},
+		}},
+		{"failed trial reopens", []step{
+			{"report", io.EOF}, {"report", io.EOF}, {"cool", nil},
+			{"allow", nil}, {"report", io.EOF},
+			{"allow", ErrCircuitOpen},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &circuitBreaker{}
+			for i, s := range tt.steps {
+				switch s.op {
+				case "report":
+					b.report(s.err, threshold, window, cooldown)
+				case "allow":
+					if err := b.allow(); !errors.Is(err, s.err) {
+						t.Fatalf("step %d: allow() = %v, want %v", i, err, s.err)
+					}
+				case "cool":
+					b.openUntil = time.Now().Add(-time.Millisecond)
+				case "age":
+					b.firstFail = time.Now().Add(-2 * window)
+				}
+			}
+		})
+	}
+}
@@ -2,23 +2,147 @@ package radcache
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/cipher"
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
 	"log"
+	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
 )
 
+// 未调用UseRedis配置客户端时，所有操作都会返回该错误
+var ErrNoClient = errors.New("radcache: no redis client configured")
+
+// 未配置客户端时使用的占位客户端，所有命令都会被limiter直接拒绝并返回ErrNoClient，不会建立连接
+var noClient = redis.NewClient(&redis.Options{
+	Limiter:            noClientLimiter{},
+	MaxRetries:         -1,
+	IdleCheckFrequency: -1,
+})
+
+type noClientLimiter struct{}
+
+func (noClientLimiter) Allow() error { return ErrNoClient }
+
+func (noClientLimiter) ReportResult(error) {}
+
+// 序列化后的值超过Options.MaxValueBytes，返回的错误包含实际大小，可以通过errors.Is判断
+var ErrValueTooLarge = errors.New("radcache: value too large")
+
+// 获取的key不存在，可以通过errors.Is(err, ErrCacheMiss)判断
+var ErrCacheMiss = errors.New("radcache: cache miss")
+
+// 包装redis.Nil，既能匹配ErrCacheMiss，也能通过errors.Is取得原始的redis.Nil
+type cacheMissError struct {
+	err error
+}
+
+func (e *cacheMissError) Error() string { return ErrCacheMiss.Error() }
+
+func (e *cacheMissError) Is(target error) bool { return target == ErrCacheMiss }
+
+func (e *cacheMissError) Unwrap() error { return e.err }
+
+// 是否为key不存在导致的错误
+func isMiss(err error) bool {
+	return errors.Is(err, redis.Nil) || errors.Is(err, ErrCacheMiss)
+}
+
+// 将redis.Nil转换为ErrCacheMiss，其他错误原样返回
+func missErr(err error) error {
+	if err == redis.Nil {
+		return &cacheMissError{err: err}
+	}
+	return err
+}
+
 type RadCache struct {
 	Ctx     context.Context
-	Db      *redis.Client
+	Db      redis.UniversalClient
 	Logger	*zap.SugaredLogger
+	// 未指定zap日志时使用的标准日志，为nil时使用log包默认的日志
+	StdLogger *log.Logger
+	// 值的序列化方式，为nil时使用json
+	Serializer Serializer
 	Options Options
+	// 用于合并同一个key并发的loader调用，防止缓存击穿
+	flight *singleflight.Group
+	// 值加密使用的AES-GCM，为nil时不加密
+	aead cipher.AEAD
+	// 进程内的一级缓存，为nil时不启用
+	local *localCache
+	// 命中统计
+	stats *stats
+	// 未关闭的订阅
+	subs *subscriptions
+	// 写缓冲，为nil时不启用
+	wb *writeBehind
+	// 本地缓存失效通知的频道，为空时不发布通知
+	invalidationChannel string
+	// 主redis不可用时使用的备用缓存，为nil时不启用
+	fallback *RadCache
+	// 熔断状态，为nil时不启用熔断
+	breaker *circuitBreaker
 }
 
 type Options struct {
 	Prefix string
+	// 通过Key组合多段key时使用的分隔符，默认为":"
+	Separator string
+	// 使用SCAN遍历key时每批的数量，默认为100
+	ScanCount int64
+	// 序列化后的值超过该字节数时使用gzip压缩，为0时不压缩
+	CompressThreshold int
+	// 设置值时在过期时间上增加[0, TTLJitter)的随机时长，避免大量key同时过期，为0时不增加
+	TTLJitter time.Duration
+	// Set、Get、Del遇到网络等临时性错误时的最大重试次数，为0时不重试
+	MaxRetries int
+	// 第一次重试前的等待时间，之后每次重试等待时间翻倍
+	RetryBackoff time.Duration
+	// Watch中被监视的key被修改导致事务失败时的最大重试次数，为0时不重试
+	TxRetries int
+	// 操作出错时的回调，op为操作名(如"Get")，key为不含前缀的key，可用于上报监控，回调后仍会写入日志
+	OnError func(op string, key string, err error)
+	// 自定义的序列化函数，可以替换为jsoniter等更快的json库，为nil时使用encoding/json
+	// 通过UseSerializer指定了序列化方式时这两个函数不生效
+	MarshalFunc   func(interface{}) ([]byte, error)
+	UnmarshalFunc func([]byte, interface{}) error
+	// GetOrSet等方法的loader返回ErrNotFound时，记录数据不存在的时长，为0时不记录
+	NotFoundTTL time.Duration
+	// 值的schema版本，不为0时会写入值的头部，读取到版本不一致的值时视为未命中
+	// 结构体变更后修改版本号即可让所有旧格式的缓存失效，没有版本号的旧值仍可正常读取
+	SchemaVersion int
+	// 读取到版本不一致的值时是否删除该key
+	DeleteStaleSchema bool
+	// SetDefault使用的过期时间，Set的exp为0时也会使用该值，为0时不过期
+	DefaultTTL time.Duration
+	// 序列化(包括压缩和加密)后的值允许的最大字节数，超过时不会写入并返回ErrValueTooLarge，为0时不限制
+	MaxValueBytes int
+	// 包含前缀的完整key允许的最大字节数，Set、Get、Del等方法遇到超长的key时返回ErrInvalidKey，为0时不限制
+	MaxKeyLength int
+	// 是否拒绝包含控制字符(如换行、制表符)的key，拒绝时返回ErrInvalidKey
+	RejectControlChars bool
+	// 每个redis命令的超时时间，基于当前的Ctx计算，超时后返回context.DeadlineExceeded，为0时不限制
+	// 只对设置了该值的实例生效，不影响共用同一客户端的其他实例，需要通过UseRedis或UseUniversalClient设置客户端
+	OpTimeout time.Duration
+	// 熔断阈值，连续BreakerThreshold次网络错误或超时后熔断，期间所有命令直接返回ErrCircuitOpen，为0时不启用
+	// 熔断状态由同一实例的WithContext、Namespace、Clone等拷贝共享，与OpTimeout一样需要通过UseRedis或UseUniversalClient设置客户端
+	BreakerThreshold int
+	// 统计连续失败的时间窗口，距第一次失败超过该时长后重新计数，为0时不限制
+	BreakerWindow time.Duration
+	// 熔断持续的时间，之后放行一个试探请求，成功则恢复，失败则继续熔断，默认为5秒
+	BreakerCooldown time.Duration
+	// 设置了UseFallback时，Set和Del是否同时写入备用缓存
+	FallbackWrites bool
+	// GetOrSet提前刷新(XFetch算法)的系数，大于0时GetOrSet会记录loader的耗时，并在key快要过期时以逐渐增大的概率提前调用loader
+	// 值越大越早刷新，通常为1，为0时不提前刷新
+	EarlyRefreshBeta float64
 }
 
 func NewDefault() *RadCache {
@@ -27,6 +151,10 @@ func NewDefault() *RadCache {
 		Options: Options{
 			Prefix: "rad_",
 		},
+		flight:  &singleflight.Group{},
+		stats:   &stats{},
+		subs:    &subscriptions{},
+		breaker: &circuitBreaker{},
 	}
 }
 
@@ -34,101 +162,555 @@ func New(opt Options) *RadCache {
 	return &RadCache{
 		Ctx:     context.Background(),
 		Options: opt,
+		flight:  &singleflight.Group{},
+		stats:   &stats{},
+		subs:    &subscriptions{},
+		breaker: &circuitBreaker{},
+	}
+}
+
+// 通过redis://形式的连接字符串创建客户端，连接不可用时直接返回错误
+func NewFromURL(url string, opt Options) (*RadCache, error) {
+	redisOpt, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	rad := New(opt)
+	rad.UseRedis(redis.NewClient(redisOpt))
+	if err := rad.Ping(); err != nil {
+		rad.Db.Close()
+		return nil, err
 	}
+	return rad, nil
+}
+
+// 通过哨兵创建高可用的客户端，连接不可用时直接返回错误
+func NewFailover(opt redis.FailoverOptions, radOpt Options) (*RadCache, error) {
+	rad := New(radOpt)
+	rad.UseRedis(redis.NewFailoverClient(&opt))
+	if err := rad.Ping(); err != nil {
+		rad.Db.Close()
+		return nil, err
+	}
+	return rad, nil
 }
 
 func (rad *RadCache) UseRedis(client *redis.Client) {
+	if client == nil {
+		rad.Db = nil
+		return
+	}
+	rad.UseUniversalClient(client)
+}
+
+// 使用任意类型的redis客户端，如单机、集群(*redis.ClusterClient)或哨兵客户端
+// 会为客户端添加实现OpTimeout和熔断的hook，没有配置时hook不做任何处理
+func (rad *RadCache) UseUniversalClient(client redis.UniversalClient) {
+	if client != nil {
+		client.AddHook(radHook{})
+	}
 	rad.Db = client
 }
 
+// 将多段key用分隔符连接并加上前缀，如Key("user", "1", "profile")得到"rad_user:1:profile"
+// 只有一段时等同于前缀直接加上key，与之前的行为保持一致
+func (rad *RadCache) Key(segments ...string) string {
+	return rad.Options.Prefix + strings.Join(segments, rad.separator())
+}
+
+func (rad *RadCache) separator() string {
+	if rad.Options.Separator == "" {
+		return ":"
+	}
+	return rad.Options.Separator
+}
+
+// 获取redis客户端，未配置时返回占位客户端，避免对nil的Db调用导致panic
+func (rad *RadCache) db() redis.UniversalClient {
+	if rad.Db == nil {
+		return noClient
+	}
+	return rad.Db
+}
+
+// 是否为集群客户端
+func (rad *RadCache) isCluster() bool {
+	_, ok := rad.Db.(*redis.ClusterClient)
+	return ok
+}
+
 func (rad *RadCache) UseZapLogger(logger *zap.SugaredLogger)  {
 	rad.Logger = logger
 }
 
-// 序列化为json
+// 返回一个使用指定context的浅拷贝，与原实例共享Db、Logger和Options，不会修改原实例
+func (rad *RadCache) WithContext(ctx context.Context) *RadCache {
+	cp := *rad
+	cp.Ctx = ctx
+	return &cp
+}
+
+// 返回一个子命名空间，前缀为当前前缀加上ns和分隔符，如"rad_"下的"tenant42"前缀为"rad_tenant42:"
+// 与原实例共享Db和Logger，不会修改原实例的Options
+func (rad *RadCache) Namespace(ns string) *RadCache {
+	cp := *rad
+	cp.Options.Prefix = rad.Key(ns) + rad.separator()
+	return &cp
+}
+
+// 返回一个浅拷贝，与原实例共享Db、Logger、本地缓存、统计和熔断状态，Options为独立的副本，修改后不会影响原实例
+// 适合为部分调用调整OpTimeout、OnError、DefaultTTL等配置
+func (rad *RadCache) Clone() *RadCache {
+	cp := *rad
+	return &cp
+}
+
+// 指定值的序列化方式
+func (rad *RadCache) UseSerializer(s Serializer) {
+	rad.Serializer = s
+}
+
+// 指定未使用zap日志时的标准日志
+func (rad *RadCache) UseStdLogger(logger *log.Logger) {
+	rad.StdLogger = logger
+}
+
+// 使用配置的序列化方式序列化，默认为json，超过压缩阈值时会进行压缩
 func (rad *RadCache) Marshal(val interface{}) (string, error) {
-	re, err := json.Marshal(val)
+	return rad.marshal(val, 0)
+}
+
+// 与Marshal相同，delta大于0时在头部记录重新计算该值的耗时
+func (rad *RadCache) marshal(val interface{}, delta time.Duration) (string, error) {
+	re, err := rad.serializer().Marshal(val)
 	if err != nil {
 		return "", err
 	}
+	re, err = rad.packDelta(re, delta)
+	if err != nil {
+		return "", err
+	}
+	if max := rad.Options.MaxValueBytes; max > 0 && len(re) > max {
+		return "", fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrValueTooLarge, len(re), max)
+	}
 	return string(re), nil
 }
 
-// 从json反序列化
+// 使用配置的序列化方式反序列化，默认为json
 func (rad *RadCache) UnMarshal(val string) (interface{}, error) {
 	var result interface{}
-	err := json.Unmarshal([]byte(val), &result)
+	err := rad.unmarshalInto(val, &result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-// 写入日志，如果未指定zap日志，则默认使用系统日志
+// 依次序列化多个值
+func (rad *RadCache) marshalAll(values []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		val, err := rad.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+// 依次反序列化多个值
+func (rad *RadCache) unmarshalAll(values []string) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		val, err := rad.UnMarshal(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+// 记录操作出错，配置了OnError时会先回调OnError再写入日志，key不存在不视为错误
+// 使用zap日志时op、key、prefix作为结构化字段写入，否则拼接在日志内容中
+func (rad *RadCache) report(op, key string, err error) {
+	if isMiss(err) {
+		if rad.Logger != nil {
+			rad.Logger.Debugw(op+" miss", "op", op, "key", key, "prefix", rad.Options.Prefix, "error", err)
+		}
+		return
+	}
+	if rad.Options.OnError != nil {
+		rad.Options.OnError(op, key, err)
+	}
+	if rad.Logger != nil {
+		rad.Logger.Errorw(op+" failed", "op", op, "key", key, "prefix", rad.Options.Prefix, "error", err)
+		return
+	}
+	rad.Error(fmt.Sprintf("radcache: %s failed key=%s prefix=%s: %v", op, key, rad.Options.Prefix, err))
+}
+
+// 写入日志，如果未指定zap日志，则默认使用系统日志，只记录错误不会退出进程
+// key不存在属于正常情况，只会以debug级别写入zap日志
 func (rad *RadCache) Error(err interface{})  {
+	if e, ok := err.(error); ok && isMiss(e) {
+		if rad.Logger != nil {
+			rad.Logger.Debug(err)
+		}
+		return
+	}
 	if rad.Logger != nil {
 		rad.Logger.Error(err)
+	}else if rad.StdLogger != nil {
+		rad.StdLogger.Println(err)
 	}else{
-		log.Fatal(err)
+		log.Println(err)
 	}
 }
 
-// 通用的设置值的方式
+// 计算实际的过期时间，配置了TTLJitter时增加随机时长，不过期的key不做处理
+func (rad *RadCache) expiration(exp time.Duration) time.Duration {
+	if exp <= 0 || rad.Options.TTLJitter <= 0 {
+		return exp
+	}
+	return exp + time.Duration(rand.Int63n(int64(rad.Options.TTLJitter)))
+}
+
+// 通用的设置值的方式，exp为0且配置了DefaultTTL时使用DefaultTTL
 func (rad *RadCache) Set(key string, value interface{}, exp time.Duration) *redis.StatusCmd {
-	val, err := rad.Marshal(value)
+	return rad.set(key, value, exp, 0)
+}
+
+// Set的实现，delta大于0时在值的头部记录重新计算该值的耗时
+func (rad *RadCache) set(key string, value interface{}, exp, delta time.Duration) *redis.StatusCmd {
+	if rad.fallback != nil && rad.Options.FallbackWrites {
+		defer rad.fallback.Set(key, value, exp)
+	}
+	if exp == 0 {
+		exp = rad.Options.DefaultTTL
+	}
+	err := rad.checkKey(key)
+	var val string
+	if err == nil {
+		val, err = rad.marshal(value, delta)
+	}
 	if err != nil {
-		cmd := redis.NewStatusCmd(rad.Ctx)
+		cmd := redis.NewStatusCmd(rad.ctx())
 		cmd.SetErr(err)
-		rad.Error(err)
+		rad.report("Set", key, err)
 		return cmd
 	}
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, val, exp)
+	if rad.wb != nil {
+		rad.wb.set(rad.Key(key), val, rad.expiration(exp))
+		return redis.NewStatusResult("OK", nil)
+	}
+	var cmd *redis.StatusCmd
+	rad.retry(func() error {
+		cmd = rad.db().Set(rad.ctx(), rad.Key(key), val, rad.expiration(exp))
+		return cmd.Err()
+	})
+	rad.invalidate(rad.Key(key))
+	return cmd
+}
+
+// 使用Options.DefaultTTL作为过期时间设置值
+func (rad *RadCache) SetDefault(key string, value interface{}) error {
+	return rad.Set(key, value, rad.Options.DefaultTTL).Err()
+}
+
+// 仅当key不存在时设置值，返回是否写入成功
+func (rad *RadCache) SetNX(key string, value interface{}, exp time.Duration) (bool, error) {
+	val, err := rad.Marshal(value)
+	if err != nil {
+		rad.report("SetNX", key, err)
+		return false, err
+	}
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().SetNX(rad.ctx(), rad.Key(key), val, rad.expiration(exp)).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetNX", key, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// 仅当key已存在时设置值，返回是否写入成功
+func (rad *RadCache) SetXX(key string, value interface{}, exp time.Duration) (bool, error) {
+	val, err := rad.Marshal(value)
+	if err != nil {
+		rad.report("SetXX", key, err)
+		return false, err
+	}
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().SetXX(rad.ctx(), rad.Key(key), val, rad.expiration(exp)).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetXX", key, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// key存在时返回{0, 当前值}，否则写入新值并返回{1}
+var setIfAbsentScript = redis.NewScript(`
+local cur = redis.call("GET", KEYS[1])
+if cur then
+	return {0, cur}
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+return {1}
+`)
+
+// 仅当key不存在时设置值，写入成功时stored为true，current为写入的value
+// key已存在时stored为false，current为已存在的值，避免SetNX失败后再Get产生的竞态
+func (rad *RadCache) SetIfAbsent(key string, value interface{}, exp time.Duration) (stored bool, current interface{}, err error) {
+	val, err := rad.Marshal(value)
+	if err != nil {
+		rad.report("SetIfAbsent", key, err)
+		return false, nil, err
+	}
+	rad.flushPending(rad.Key(key))
+	result, err := setIfAbsentScript.Run(rad.ctx(), rad.db(), []string{rad.Key(key)}, val, rad.expiration(exp).Milliseconds()).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetIfAbsent", key, err)
+		return false, nil, err
+	}
+	values, _ := result.([]interface{})
+	if len(values) == 2 {
+		str, _ := values[1].(string)
+		current, err = rad.UnMarshal(str)
+		if err != nil {
+			rad.report("SetIfAbsent", key, err)
+			return false, nil, err
+		}
+		return false, current, nil
+	}
+	return true, value, nil
 }
 
 // 设置一个类型为string的缓存
 func (rad *RadCache) SetString(key string, value string, exp time.Duration) *redis.StatusCmd {
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, value, exp)
+	rad.flushPending(rad.Key(key))
+	cmd := rad.db().Set(rad.ctx(), rad.Key(key), value, rad.expiration(exp))
+	rad.invalidate(rad.Key(key))
+	return cmd
 }
 
 func (rad *RadCache) SetInt(key string, value int, exp time.Duration) *redis.StatusCmd {
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, value, exp)
+	rad.flushPending(rad.Key(key))
+	cmd := rad.db().Set(rad.ctx(), rad.Key(key), value, rad.expiration(exp))
+	rad.invalidate(rad.Key(key))
+	return cmd
 }
 
 func (rad *RadCache) SetInt64(key string, value int64, exp time.Duration) *redis.StatusCmd {
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, value, exp)
+	rad.flushPending(rad.Key(key))
+	cmd := rad.db().Set(rad.ctx(), rad.Key(key), value, rad.expiration(exp))
+	rad.invalidate(rad.Key(key))
+	return cmd
 }
 
 func (rad *RadCache) SetBool(key string, value bool, exp time.Duration) *redis.StatusCmd {
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, value, exp)
+	rad.flushPending(rad.Key(key))
+	cmd := rad.db().Set(rad.ctx(), rad.Key(key), value, rad.expiration(exp))
+	rad.invalidate(rad.Key(key))
+	return cmd
 }
 
 func (rad *RadCache) SetFloat32(key string, value float32, exp time.Duration) *redis.StatusCmd {
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, value, exp)
+	rad.flushPending(rad.Key(key))
+	cmd := rad.db().Set(rad.ctx(), rad.Key(key), value, rad.expiration(exp))
+	rad.invalidate(rad.Key(key))
+	return cmd
 }
 
 func (rad *RadCache) SetFloat64(key string, value float64, exp time.Duration) *redis.StatusCmd {
-	return rad.Db.Set(rad.Ctx, rad.Options.Prefix+key, value, exp)
+	rad.flushPending(rad.Key(key))
+	cmd := rad.db().Set(rad.ctx(), rad.Key(key), value, rad.expiration(exp))
+	rad.invalidate(rad.Key(key))
+	return cmd
 }
 
 // 通用的获取值的方式
 func (rad *RadCache) Get(key string) (interface{}, error) {
-	result, err := rad.Db.Get(rad.Ctx, rad.Options.Prefix+key).Result()
+	result, err := rad.fetch("Get", key)
+	if err != nil {
+		rad.report("Get", key, err)
+		return nil, err
+	}
+	val, err := rad.UnMarshal(result)
+	if err != nil {
+		rad.dropStale(key, err)
+		return nil, err
+	}
+	return val, nil
+}
+
+// 获取缓存，如果不存在则调用loader获取值并写入缓存，loader出错时不会写入缓存
+// 同一个key并发未命中时只会有一个goroutine调用loader，其余goroutine共享其结果
+// loader返回ErrNotFound且配置了NotFoundTTL时会记录数据不存在，在此期间直接返回ErrNotFound
+func (rad *RadCache) GetOrSet(key string, exp time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	if rad.Options.EarlyRefreshBeta > 0 {
+		return rad.getOrSetEarly(key, exp, loader)
+	}
+	result, err := rad.Get(key)
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, ErrCacheMiss) || errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return rad.load(key, exp, loader)
+}
+
+// 调用loader并写入缓存，通过singleflight合并同一个key的并发调用
+func (rad *RadCache) load(key string, exp time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	return rad.loadShared(rad.Key(key), key, exp, loader)
+}
+
+// 与load相同，但使用指定的flightKey合并并发调用，返回值类型不同的loader需要使用不同的flightKey
+func (rad *RadCache) loadShared(flightKey, key string, exp time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	fn := rad.loadFunc(key, exp, loader)
+	if rad.flight == nil {
+		return fn()
+	}
+	val, err, _ := rad.flight.Do(flightKey, fn)
+	return val, err
+}
+
+// 返回调用loader并将结果写入缓存的函数，loader出错时不写入
+func (rad *RadCache) loadFunc(key string, exp time.Duration, loader func() (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		start := time.Now()
+		val, err := loader()
+		if err != nil {
+			if errors.Is(err, ErrNotFound) && rad.Options.NotFoundTTL > 0 {
+				rad.SetNotFound(key, rad.Options.NotFoundTTL)
+			}
+			return nil, err
+		}
+		var delta time.Duration
+		if rad.Options.EarlyRefreshBeta > 0 {
+			delta = time.Since(start)
+		}
+		if err := rad.set(key, val, exp, delta).Err(); err != nil {
+			rad.report("GetOrSet", key, err)
+		}
+		return val, nil
+	}
+}
+
+// 在后台调用loader刷新缓存，同一个key同时只会有一个刷新在执行
+// 使用独立的context，避免请求结束后context被取消导致刷新失败
+func (rad *RadCache) refresh(key string, exp time.Duration, loader func() (interface{}, error)) {
+	bg := rad.WithContext(context.Background())
+	fn := bg.loadFunc(key, exp, loader)
+	if rad.flight == nil {
+		go fn()
+		return
+	}
+	rad.flight.DoChan(rad.Key(key), fn)
+}
+
+// 获取值并直接反序列化到dest中，dest必须为指针，key不存在时返回ErrCacheMiss
+func (rad *RadCache) GetInto(key string, dest interface{}) error {
+	result, err := rad.fetch("GetInto", key)
+	if err != nil {
+		rad.report("GetInto", key, err)
+		return err
+	}
+	err = rad.unmarshalInto(result, dest)
+	if err != nil {
+		rad.dropStale(key, err)
+		rad.report("GetInto", key, err)
+	}
+	return err
+}
+
+// 设置新值并返回旧值，key原本不存在时返回nil
+func (rad *RadCache) GetSet(key string, value interface{}) (interface{}, error) {
+	val, err := rad.Marshal(value)
 	if err != nil {
-		rad.Error(err)
+		rad.report("GetSet", key, err)
+		return nil, err
+	}
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().GetSet(rad.ctx(), rad.Key(key), val).Result()
+	rad.invalidate(rad.Key(key))
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		rad.report("GetSet", key, err)
+		return nil, err
+	}
+	return rad.UnMarshal(result)
+}
+
+// 获取值并删除该key，key不存在时返回nil
+// 优先使用GETDEL命令(redis 6.2+)，低版本redis会回退为MULTI/EXEC中的GET和DEL
+func (rad *RadCache) GetDel(key string) (interface{}, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().GetDel(rad.ctx(), rad.Key(key)).Result()
+	if err != nil && err != redis.Nil && strings.Contains(err.Error(), "unknown command") {
+		var cmd *redis.StringCmd
+		_, err = rad.db().TxPipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+			cmd = pipe.Get(rad.ctx(), rad.Key(key))
+			pipe.Del(rad.ctx(), rad.Key(key))
+			return nil
+		})
+		if err == nil || err == redis.Nil {
+			result, err = cmd.Result()
+		}
+	}
+	rad.invalidate(rad.Key(key))
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		rad.report("GetDel", key, err)
 		return nil, err
 	}
 	return rad.UnMarshal(result)
 }
 
 func (rad *RadCache) GetString(key string) (string, error) {
-	result, err := rad.Db.Get(rad.Ctx, rad.Options.Prefix+key).Result()
+	result, err := rad.fetch("GetString", key)
 	if err != nil {
-		rad.Error(err)
+		rad.report("GetString", key, err)
 		return "", err
 	}
 	return result, nil
 }
 
+// 获取key保存的序列化后的字符串，不做反序列化，适合直接输出缓存的JSON
+// 会去掉Set写入的头部(版本号、重新计算耗时)并还原压缩和加密，SetString等写入的原始值原样返回
+// key不存在、被SetNotFound记录为不存在或SchemaVersion不一致时返回false且error为nil，只有redis出错或无法解码时才返回error
+func (rad *RadCache) GetRaw(key string) (string, bool, error) {
+	result, err := rad.fetch("GetRaw", key)
+	if err == nil {
+		var data []byte
+		data, err = rad.unpack([]byte(result))
+		result = string(data)
+		rad.dropStale(key, err)
+	}
+	if errors.Is(err, ErrCacheMiss) {
+		return "", false, nil
+	}
+	if err != nil {
+		rad.report("GetRaw", key, err)
+		return "", false, err
+	}
+	return result, true, nil
+}
+
 func (rad *RadCache) GetStringOrDefault(key string, val string) string {
 	result,err := rad.GetString(key)
 	if err != nil {
@@ -138,9 +720,13 @@ func (rad *RadCache) GetStringOrDefault(key string, val string) string {
 }
 
 func (rad *RadCache) GetInt(key string) (int,error) {
-	result,err := rad.Db.Get(rad.Ctx,rad.Options.Prefix+key).Int()
+	val, err := rad.fetch("GetInt", key)
+	var result int
+	if err == nil {
+		result, err = strconv.Atoi(val)
+	}
 	if err != nil {
-		rad.Logger.Error(err)
+		rad.report("GetInt", key, err)
 		return -1, err
 	}
 	return result,nil
@@ -155,9 +741,13 @@ func (rad *RadCache) GetIntOrDefault(key string, val int) int {
 }
 
 func (rad *RadCache) GetInt64(key string) (int64,error) {
-	result,err := rad.Db.Get(rad.Ctx,rad.Options.Prefix+key).Int64()
+	val, err := rad.fetch("GetInt64", key)
+	var result int64
+	if err == nil {
+		result, err = strconv.ParseInt(val, 10, 64)
+	}
 	if err != nil {
-		rad.Logger.Error(err)
+		rad.report("GetInt64", key, err)
 		return -1, err
 	}
 	return result,nil
@@ -172,9 +762,13 @@ func (rad *RadCache) GetInt64OrDefault(key string, val int64) int64 {
 }
 
 func (rad *RadCache) GetBool(key string) (bool,error) {
-	result,err := rad.Db.Get(rad.Ctx,rad.Options.Prefix+key).Bool()
+	val, err := rad.fetch("GetBool", key)
+	var result bool
+	if err == nil {
+		result, err = strconv.ParseBool(val)
+	}
 	if err != nil {
-		rad.Logger.Error(err)
+		rad.report("GetBool", key, err)
 		return false, err
 	}
 	return result,nil
@@ -189,9 +783,15 @@ func (rad *RadCache) GetBoolOrDefault(key string, val bool) bool {
 }
 
 func (rad *RadCache) GetFloat32(key string) (float32,error) {
-	result,err := rad.Db.Get(rad.Ctx,rad.Options.Prefix+key).Float32()
+	val, err := rad.fetch("GetFloat32", key)
+	var result float32
+	if err == nil {
+		var f float64
+		f, err = strconv.ParseFloat(val, 32)
+		result = float32(f)
+	}
 	if err != nil {
-		rad.Logger.Error(err)
+		rad.report("GetFloat32", key, err)
 		return 0.0, err
 	}
 	return result,nil
@@ -206,9 +806,13 @@ func (rad *RadCache) GetFloat32OrDefault(key string, val float32) float32 {
 }
 
 func (rad *RadCache) GetFloat64(key string) (float64,error) {
-	result,err := rad.Db.Get(rad.Ctx,rad.Options.Prefix+key).Float64()
+	val, err := rad.fetch("GetFloat64", key)
+	var result float64
+	if err == nil {
+		result, err = strconv.ParseFloat(val, 64)
+	}
 	if err != nil {
-		rad.Logger.Error(err)
+		rad.report("GetFloat64", key, err)
 		return 0.0, err
 	}
 	return result,nil
@@ -224,9 +828,20 @@ func (rad *RadCache) GetFloat64OrDefault(key string, val float64) float64 {
 
 // 删除一个指定的缓存
 func (rad *RadCache) Del(key string) error {
-	err := rad.Db.Del(rad.Ctx, rad.Options.Prefix+key).Err()
+	if rad.fallback != nil && rad.Options.FallbackWrites {
+		defer rad.fallback.Del(key)
+	}
+	if err := rad.checkKey(key); err != nil {
+		rad.report("Del", key, err)
+		return err
+	}
+	rad.flushPending(rad.Key(key))
+	err := rad.retry(func() error {
+		return rad.db().Del(rad.ctx(), rad.Key(key)).Err()
+	})
+	rad.invalidate(rad.Key(key))
 	if err != nil {
-		rad.Error(err)
+		rad.report("Del", key, err)
 	}
 	return err
 }
@@ -235,21 +850,94 @@ func (rad *RadCache) Del(key string) error {
 func (rad *RadCache) DelAny(key ...string) error {
 	var keys []string
 	for _,v := range key {
-		keys = append(keys,rad.Options.Prefix+v)
+		keys = append(keys,rad.Key(v))
+	}
+	var err error
+	rad.flushPending(keys...)
+	if rad.isCluster() {
+		// 集群中的多个key可能不在同一个slot，需要逐个删除
+		_, err = rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+			for _, k := range keys {
+				pipe.Del(rad.ctx(), k)
+			}
+			return nil
+		})
+	} else {
+		err = rad.db().Del(rad.ctx(), keys...).Err()
 	}
-	err := rad.Db.Del(rad.Ctx, keys...).Err()
+	rad.invalidate(keys...)
 	if err != nil {
-		rad.Error(err)
+		rad.report("DelAny", strings.Join(key, ","), err)
 	}
 	return err
 }
 
+// 关闭所有订阅并关闭redis客户端，未配置客户端时也可以安全调用
+// 通过WithContext、Namespace得到的实例共享同一个客户端，关闭任意一个都会使其他实例不可用
+func (rad *RadCache) Close() error {
+	rad.subs.closeAll()
+	rad.stopWriteBehind()
+	if rad.Db == nil {
+		return nil
+	}
+	return rad.Db.Close()
+}
+
+// 检查redis连接是否可用，未配置客户端时返回ErrNoClient
+func (rad *RadCache) Ping() error {
+	return rad.db().Ping(rad.ctx()).Err()
+}
+
 // 判断是否存在指定key
 func (rad *RadCache) Exist(key string) bool {
-	result := rad.Db.Exists(rad.Ctx,rad.Options.Prefix+key)
+	result := rad.db().Exists(rad.ctx(),rad.Key(key))
 	if result.Val() == 1 {
 		return true
 	}else{
 		return false
 	}
-}
\ No newline at end of file
+}
+// 判断多个key中有多少个存在，同一个key传入多次时会被重复计数
+func (rad *RadCache) ExistMany(keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	var prefixed []string
+	for _, v := range keys {
+		prefixed = append(prefixed, rad.Key(v))
+	}
+	if !rad.isCluster() {
+		result, err := rad.db().Exists(rad.ctx(), prefixed...).Result()
+		if err != nil {
+			rad.report("ExistMany", strings.Join(keys, ","), err)
+			return 0, err
+		}
+		return result, nil
+	}
+	// 集群中的多个key可能不在同一个slot，需要逐个判断
+	cmds, err := rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		for _, k := range prefixed {
+			pipe.Exists(rad.ctx(), k)
+		}
+		return nil
+	})
+	if err != nil {
+		rad.report("ExistMany", strings.Join(keys, ","), err)
+		return 0, err
+	}
+	var count int64
+	for _, cmd := range cmds {
+		count += cmd.(*redis.IntCmd).Val()
+	}
+	return count, nil
+}
+
+// 判断是否存在指定key，与Exist不同的是会返回redis的错误
+func (rad *RadCache) ExistE(key string) (bool, error) {
+	result, err := rad.db().Exists(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		rad.report("ExistE", key, err)
+		return false, err
+	}
+	return result == 1, nil
+}
@@ -0,0 +1,89 @@
+package radcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestGetIntWithoutLogger(t *testing.T) {
+	t.Run("no client", func(t *testing.T) {
+		rad := NewDefault()
+		if _, err := rad.GetInt("missing"); !errors.Is(err, ErrNoClient) {
+			t.Fatalf("GetInt error = %v, want ErrNoClient", err)
+		}
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		rad := NewDefault()
+		rad.UseRedis(redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1}))
+		defer rad.Close()
+		if _, err := rad.GetInt("missing"); err == nil {
+			t.Fatal("GetInt returned nil error for an unreachable server")
+		}
+	})
+}
+
+func TestGetNotFoundMarker(t *testing.T) {
+	rad := NewDefault()
+	rad.UseLocalCache(16, time.Minute)
+	rad.local.set(rad.Key("k"), string([]byte{headerMark, flagNotFound}))
+
+	if _, err := rad.GetString("k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString error = %v, want ErrNotFound", err)
+	}
+	if _, err := rad.GetBytes("k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBytes error = %v, want ErrNotFound", err)
+	}
+	if _, err := rad.GetInt("k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetInt error = %v, want ErrNotFound", err)
+	}
+	if _, found, err := rad.GetRaw("k"); found || err != nil {
+		t.Errorf("GetRaw = found %v, error %v, want false and nil", found, err)
+	}
+}
+
+func TestGetRawStripsHeader(t *testing.T) {
+	rad := NewDefault()
+	rad.Options.SchemaVersion = 2
+	rad.Options.CompressThreshold = 1
+	rad.UseLocalCache(16, time.Minute)
+	val, err := rad.marshal("hello", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rad.local.set(rad.Key("k"), val)
+
+	raw, found, err := rad.GetRaw("k")
+	if err != nil || !found {
+		t.Fatalf("GetRaw = found %v, error %v, want true and nil", found, err)
+	}
+	if raw != `"hello"` {
+		t.Fatalf("GetRaw = %q, want %q", raw, `"hello"`)
+	}
+	rad.Options.SchemaVersion = 3
+	if _, found, err := rad.GetRaw("k"); found || err != nil {
+		t.Fatalf("GetRaw with another schema version = found %v, error %v, want false and nil", found, err)
+	}
+}
+
+func TestGetWithTTLReadsLocal(t *testing.T) {
+	rad := NewDefault()
+	rad.UseLocalCache(16, time.Minute)
+	val, err := rad.Marshal("v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rad.local.set(rad.Key("k"), val)
+
+	// 值来自本地缓存，没有客户端时获取不到剩余过期时间
+	got, ttl, err := rad.GetWithTTL("k")
+	if err != nil || got != "v" || ttl != TTLNoExpire {
+		t.Fatalf("GetWithTTL = %v, %v, %v, want v, TTLNoExpire, nil", got, ttl, err)
+	}
+	rad.Options.MaxKeyLength = 4
+	if _, _, err := rad.GetWithTTL("long-key"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("GetWithTTL error = %v, want ErrInvalidKey", err)
+	}
+}
@@ -0,0 +1,69 @@
+package radcache
+
+import (
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 仅当key的值等于期望值时才删除
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// 仅当key的值等于期望值时才写入新值，ARGV[3]为过期毫秒数，为0时不过期
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// 仅当key当前的值等于expected时才删除，返回是否删除，用于一次性token等场景
+// expected会按Set相同的方式序列化后与保存的值比较，启用加密时序列化结果不固定，无法匹配
+func (rad *RadCache) CompareAndDelete(key string, expected interface{}) (bool, error) {
+	val, err := rad.Marshal(expected)
+	if err != nil {
+		rad.report("CompareAndDelete", key, err)
+		return false, err
+	}
+	rad.flushPending(rad.Key(key))
+	result, err := compareAndDeleteScript.Run(rad.ctx(), rad.db(), []string{rad.Key(key)}, val).Int64()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("CompareAndDelete", key, err)
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// 仅当key当前的值等于expected时才设置为new，返回是否设置成功，key不存在时返回false
+// expected的比较方式与CompareAndDelete相同
+func (rad *RadCache) CompareAndSwap(key string, expected, new interface{}, exp time.Duration) (bool, error) {
+	old, err := rad.Marshal(expected)
+	if err != nil {
+		rad.report("CompareAndSwap", key, err)
+		return false, err
+	}
+	val, err := rad.Marshal(new)
+	if err != nil {
+		rad.report("CompareAndSwap", key, err)
+		return false, err
+	}
+	rad.flushPending(rad.Key(key))
+	result, err := compareAndSwapScript.Run(rad.ctx(), rad.db(), []string{rad.Key(key)}, old, val, rad.expiration(exp).Milliseconds()).Int64()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("CompareAndSwap", key, err)
+		return false, err
+	}
+	return result == 1, nil
+}
@@ -0,0 +1,163 @@
+package radcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// 值的头部标记，0xc1在json和msgpack中都不会作为开头出现，可以区分出带头部的值
+// 带头部的值格式为：标记(1字节) + flags(1字节) + [schema版本(uvarint)] + [重新计算耗时(uvarint毫秒)] + 数据
+const headerMark byte = 0xc1
+
+const (
+	// 数据经过了gzip压缩
+	flagGzip byte = 1 << iota
+	// 数据经过了AES-GCM加密
+	flagEncrypted
+	// 记录数据不存在，没有实际的数据
+	flagNotFound
+	// flags之后带有schema版本号
+	flagVersioned
+	// 带有loader重新计算该值的耗时，用于提前刷新
+	flagDelta
+)
+
+var (
+	ErrInvalidEncryptionKey = errors.New("radcache: encryption key must be 32 bytes")
+	ErrNoEncryptionKey      = errors.New("radcache: value is encrypted but no encryption key configured")
+)
+
+// 指定32字节的AES密钥，之后写入的值都会使用AES-GCM加密，未加密的旧值仍可正常读取
+func (rad *RadCache) UseEncryptionKey(key []byte) error {
+	if len(key) != 32 {
+		return ErrInvalidEncryptionKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	rad.aead = aead
+	return nil
+}
+
+// 对序列化后的数据进行压缩、加密等处理，不需要处理时原样返回
+func (rad *RadCache) pack(data []byte) ([]byte, error) {
+	return rad.packDelta(data, 0)
+}
+
+// 与pack相同，delta大于0时在头部记录重新计算该值的耗时
+func (rad *RadCache) packDelta(data []byte, delta time.Duration) ([]byte, error) {
+	var flags byte
+	if rad.Options.CompressThreshold > 0 && len(data) > rad.Options.CompressThreshold {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+		flags |= flagGzip
+	}
+	if rad.aead != nil {
+		nonce := make([]byte, rad.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		data = rad.aead.Seal(nonce, nonce, data, nil)
+		flags |= flagEncrypted
+	}
+	header := []byte{headerMark, flags}
+	if rad.Options.SchemaVersion != 0 {
+		header[1] |= flagVersioned
+		buf := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(buf, uint64(rad.Options.SchemaVersion))
+		header = append(header, buf[:n]...)
+	}
+	if ms := delta.Milliseconds(); ms > 0 {
+		header[1] |= flagDelta
+		buf := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(buf, uint64(ms))
+		header = append(header, buf[:n]...)
+	}
+	if header[1] == 0 {
+		return data, nil
+	}
+	return append(header, data...), nil
+}
+
+// 还原pack处理过的数据，没有头部的值原样返回
+func (rad *RadCache) unpack(data []byte) ([]byte, error) {
+	data, _, err := rad.unpackDelta(data)
+	return data, err
+}
+
+// 与unpack相同，同时返回头部记录的重新计算耗时，没有记录时为0
+func (rad *RadCache) unpackDelta(data []byte) ([]byte, time.Duration, error) {
+	if len(data) < 2 || data[0] != headerMark {
+		return data, 0, nil
+	}
+	flags := data[1]
+	data = data[2:]
+	if flags&flagNotFound != 0 {
+		return nil, 0, ErrNotFound
+	}
+	if flags&flagVersioned != 0 {
+		version, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, 0, errors.New("radcache: invalid schema version")
+		}
+		if version != uint64(rad.Options.SchemaVersion) {
+			return nil, 0, ErrSchemaMismatch
+		}
+		data = data[n:]
+	}
+	var delta time.Duration
+	if flags&flagDelta != 0 {
+		ms, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, 0, errors.New("radcache: invalid recompute delta")
+		}
+		delta = time.Duration(ms) * time.Millisecond
+		data = data[n:]
+	}
+	if flags&flagEncrypted != 0 {
+		if rad.aead == nil {
+			return nil, 0, ErrNoEncryptionKey
+		}
+		size := rad.aead.NonceSize()
+		if len(data) < size {
+			return nil, 0, errors.New("radcache: encrypted value too short")
+		}
+		var err error
+		data, err = rad.aead.Open(nil, data[:size], data[size:], nil)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if flags&flagGzip != 0 {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, err
+		}
+		defer r.Close()
+		data, err = ioutil.ReadAll(r)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return data, delta, nil
+}
@@ -0,0 +1,92 @@
+package radcache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPackRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	data := []byte(`{"name":"radcache","tags":["a","b","c"]}`)
+	tests := []struct {
+		name      string
+		compress  int
+		encrypt   bool
+		version   int
+		delta     time.Duration
+		wantDelta time.Duration
+	}{
+		{name: "plain"},
+		{name: "gzip", compress: 1},
+		{name: "encrypted", encrypt: true},
+		{name: "versioned", version: 3},
+		{name: "delta", delta: 1500 * time.Millisecond, wantDelta: 1500 * time.Millisecond},
+		{name: "delta below a millisecond", delta: time.Microsecond},
+		{name: "all", compress: 1, encrypt: true, version: 300, delta: time.Minute, wantDelta: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rad := NewDefault()
+			rad.Options.CompressThreshold = tt.compress
+			rad.Options.SchemaVersion = tt.version
+			if tt.encrypt {
+				if err := rad.UseEncryptionKey(key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			packed, err := rad.packDelta(data, tt.delta)
+			if err != nil {
+				t.Fatalf("packDelta error = %v", err)
+			}
+			got, delta, err := rad.unpackDelta(packed)
+			if err != nil {
+				t.Fatalf("unpackDelta error = %v", err)
+			}
+			if !bytes.Equal(got, data) || delta != tt.wantDelta {
+				t.Fatalf("unpackDelta = %q, %v, want %q, %v", got, delta, data, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	writer := NewDefault()
+	writer.Options.SchemaVersion = 1
+	versioned, err := writer.packDelta([]byte(`"v"`), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.UseEncryptionKey(bytes.Repeat([]byte{1}, 32)); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := writer.pack([]byte(`"v"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		version int
+		data    []byte
+		want    error
+	}{
+		{"version mismatch", 2, versioned, ErrSchemaMismatch},
+		{"unversioned reader", 0, versioned, ErrSchemaMismatch},
+		{"not found marker", 0, []byte{headerMark, flagNotFound}, ErrNotFound},
+		{"missing encryption key", 1, encrypted, ErrNoEncryptionKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rad := NewDefault()
+			rad.Options.SchemaVersion = tt.version
+			if _, _, err := rad.unpackDelta(tt.data); !errors.Is(err, tt.want) {
+				t.Fatalf("unpackDelta error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	// 不带头部的值原样返回
+	if got, _, err := NewDefault().unpackDelta([]byte(`"v"`)); err != nil || string(got) != `"v"` {
+		t.Fatalf("unpackDelta = %q, %v, want the value unchanged", got, err)
+	}
+}
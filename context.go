@@ -0,0 +1,23 @@
+package radcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 使用指定的context获取值，等同于rad.WithContext(ctx).Get(key)
+func (rad *RadCache) GetCtx(ctx context.Context, key string) (interface{}, error) {
+	return rad.WithContext(ctx).Get(key)
+}
+
+// 使用指定的context设置值，等同于rad.WithContext(ctx).Set(key, value, exp)
+func (rad *RadCache) SetCtx(ctx context.Context, key string, value interface{}, exp time.Duration) *redis.StatusCmd {
+	return rad.WithContext(ctx).Set(key, value, exp)
+}
+
+// 使用指定的context删除key，等同于rad.WithContext(ctx).Del(key)
+func (rad *RadCache) DelCtx(ctx context.Context, key string) error {
+	return rad.WithContext(ctx).Del(key)
+}
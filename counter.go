@@ -0,0 +1,85 @@
+package radcache
+
+// 将key的值加1，返回操作后的值
+func (rad *RadCache) Incr(key string) (int64, error) {
+	if rad.wb != nil {
+		result, err := rad.bufferIncr(key, 1)
+		if err != nil {
+			rad.report("Incr", key, err)
+		}
+		return result, err
+	}
+	result, err := rad.db().Incr(rad.ctx(), rad.Key(key)).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("Incr", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 将key的值减1，返回操作后的值
+func (rad *RadCache) Decr(key string) (int64, error) {
+	if rad.wb != nil {
+		result, err := rad.bufferIncr(key, -1)
+		if err != nil {
+			rad.report("Decr", key, err)
+		}
+		return result, err
+	}
+	result, err := rad.db().Decr(rad.ctx(), rad.Key(key)).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("Decr", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 将key的值加n，返回操作后的值
+func (rad *RadCache) IncrBy(key string, n int64) (int64, error) {
+	if rad.wb != nil {
+		result, err := rad.bufferIncr(key, n)
+		if err != nil {
+			rad.report("IncrBy", key, err)
+		}
+		return result, err
+	}
+	result, err := rad.db().IncrBy(rad.ctx(), rad.Key(key), n).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("IncrBy", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 将key的值减n，返回操作后的值
+func (rad *RadCache) DecrBy(key string, n int64) (int64, error) {
+	if rad.wb != nil {
+		result, err := rad.bufferIncr(key, -n)
+		if err != nil {
+			rad.report("DecrBy", key, err)
+		}
+		return result, err
+	}
+	result, err := rad.db().DecrBy(rad.ctx(), rad.Key(key), n).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("DecrBy", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 将key的值加上浮点数n，返回操作后的值
+func (rad *RadCache) IncrByFloat(key string, n float64) (float64, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().IncrByFloat(rad.ctx(), rad.Key(key), n).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("IncrByFloat", key, err)
+		return 0.0, err
+	}
+	return result, nil
+}
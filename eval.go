@@ -0,0 +1,52 @@
+package radcache
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 缓存EvalSha使用过的脚本及其SHA1，避免每次调用都重新计算
+var scripts sync.Map
+
+// 执行Lua脚本，keys会自动加上前缀后作为KEYS传入，args作为ARGV原样传入
+// 脚本返回nil时结果为nil且error为nil；脚本可能修改keys，因此会移除这些key的本地缓存
+func (rad *RadCache) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	prefixed := rad.prefixKeys(keys)
+	rad.flushPending(prefixed...)
+	result, err := rad.db().Eval(rad.ctx(), script, prefixed, args...).Result()
+	return rad.evalResult("Eval", keys, prefixed, result, err)
+}
+
+// 与Eval相同，但优先使用EVALSHA执行，redis中没有缓存该脚本时自动改用EVAL，适合频繁执行的脚本
+func (rad *RadCache) EvalSha(script string, keys []string, args ...interface{}) (interface{}, error) {
+	s, ok := scripts.Load(script)
+	if !ok {
+		s, _ = scripts.LoadOrStore(script, redis.NewScript(script))
+	}
+	prefixed := rad.prefixKeys(keys)
+	rad.flushPending(prefixed...)
+	result, err := s.(*redis.Script).Run(rad.ctx(), rad.db(), prefixed, args...).Result()
+	return rad.evalResult("EvalSha", keys, prefixed, result, err)
+}
+
+func (rad *RadCache) prefixKeys(keys []string) []string {
+	prefixed := make([]string, 0, len(keys))
+	for _, k := range keys {
+		prefixed = append(prefixed, rad.Key(k))
+	}
+	return prefixed
+}
+
+func (rad *RadCache) evalResult(op string, keys, prefixed []string, result interface{}, err error) (interface{}, error) {
+	rad.invalidate(prefixed...)
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		rad.report(op, strings.Join(keys, ","), err)
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package radcache
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// key存在但没有设置过期时间
+	TTLNoExpire time.Duration = -1
+	// key不存在
+	TTLNotExist time.Duration = -2
+)
+
+// 获取key剩余的过期时间，未设置过期时间时返回TTLNoExpire，key不存在时返回TTLNotExist
+func (rad *RadCache) TTL(key string) (time.Duration, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().TTL(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		rad.report("TTL", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 重新设置key的过期时间，key不存在时返回false
+func (rad *RadCache) Expire(key string, exp time.Duration) (bool, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().Expire(rad.ctx(), rad.Key(key), exp).Result()
+	if err != nil {
+		rad.report("Expire", key, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// 移除key的过期时间，key不存在或未设置过期时间时返回false
+func (rad *RadCache) Persist(key string) (bool, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().Persist(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		rad.report("Persist", key, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// 设置key在指定的时间点过期，key不存在时返回false
+func (rad *RadCache) ExpireAt(key string, t time.Time) (bool, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().ExpireAt(rad.ctx(), rad.Key(key), t).Result()
+	if err != nil {
+		rad.report("ExpireAt", key, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// 通过一个pipeline批量重新设置多个key的过期时间，返回成功更新过期时间的key数量
+func (rad *RadCache) Touch(exp time.Duration, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	for _, k := range keys {
+		rad.flushPending(rad.Key(k))
+	}
+	cmds, err := rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		for _, k := range keys {
+			pipe.Expire(rad.ctx(), rad.Key(k), exp)
+		}
+		return nil
+	})
+	if err != nil {
+		rad.report("Touch", strings.Join(keys, ","), err)
+		return 0, err
+	}
+	var count int64
+	for _, cmd := range cmds {
+		if cmd.(*redis.BoolCmd).Val() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// 获取值和剩余的过期时间，key不存在时返回ErrCacheMiss
+// 值通过fetch获取(与Get相同，会经过写缓冲、本地缓存、重试和fallback)，之后再通过TTL获取剩余过期时间
+// 获取剩余过期时间失败时(如值来自fallback)只上报错误，返回的过期时间为TTLNoExpire
+func (rad *RadCache) GetWithTTL(key string) (interface{}, time.Duration, error) {
+	val, err := rad.fetch("GetWithTTL", key)
+	if err != nil {
+		rad.report("GetWithTTL", key, err)
+		return nil, 0, err
+	}
+	result, err := rad.UnMarshal(val)
+	if err != nil {
+		rad.dropStale(key, err)
+		rad.report("GetWithTTL", key, err)
+		return nil, 0, err
+	}
+	var ttl time.Duration
+	err = rad.retry(func() (err error) {
+		ttl, err = rad.db().TTL(rad.ctx(), rad.Key(key)).Result()
+		return err
+	})
+	if err != nil {
+		rad.report("GetWithTTL", key, err)
+		return result, TTLNoExpire, nil
+	}
+	return result, ttl, nil
+}
+
+// 获取缓存，剩余过期时间小于staleWindow时仍直接返回当前值，同时在后台调用loader刷新缓存
+// key不存在时同步调用loader并写入缓存，loader出错时不会写入缓存
+func (rad *RadCache) GetOrRefresh(key string, exp, staleWindow time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	result, ttl, err := rad.GetWithTTL(key)
+	if err == nil {
+		if ttl >= 0 && ttl < staleWindow {
+			rad.refresh(key, exp, loader)
+		}
+		return result, nil
+	}
+	if !errors.Is(err, ErrCacheMiss) || errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return rad.load(key, exp, loader)
+}
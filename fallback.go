@@ -0,0 +1,18 @@
+package radcache
+
+import "errors"
+
+// 指定备用的RadCache，主redis出现网络错误、超时或熔断时，读取会改为从secondary读取，key不存在不会触发
+// Options.FallbackWrites为true时Set和Del还会同时写入secondary，否则secondary只用于读取
+// secondary不应再设置fallback，传入nil时取消
+func (rad *RadCache) UseFallback(secondary *RadCache) {
+	if secondary == rad {
+		return
+	}
+	rad.fallback = secondary
+}
+
+// 是否为redis不可用导致的错误
+func isUnavailable(err error) bool {
+	return isBreakerFailure(err) || errors.Is(err, ErrCircuitOpen)
+}
@@ -0,0 +1,161 @@
+package radcache
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 泛型方式设置值，值会通过Marshal序列化
+func SetValue[T any](rad *RadCache, key string, v T, exp time.Duration) error {
+	val, err := rad.Marshal(v)
+	if err != nil {
+		rad.report("SetValue", key, err)
+		return err
+	}
+	rad.flushPending(rad.Key(key))
+	err = rad.db().Set(rad.ctx(), rad.Key(key), val, rad.expiration(exp)).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetValue", key, err)
+	}
+	return err
+}
+
+// 泛型方式获取值，直接反序列化为T，出错时返回T的零值
+func GetValue[T any](rad *RadCache, key string) (T, error) {
+	var result T
+	val, err := rad.fetch("GetValue", key)
+	if err != nil {
+		rad.report("GetValue", key, err)
+		return result, err
+	}
+	err = rad.unmarshalInto(val, &result)
+	if err != nil {
+		rad.dropStale(key, err)
+		rad.report("GetValue", key, err)
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// 泛型方式获取值，未命中或出错时返回def
+func GetOrDefault[T any](rad *RadCache, key string, def T) T {
+	result, err := GetValue[T](rad, key)
+	if err != nil {
+		return def
+	}
+	return result
+}
+
+// 泛型方式的GetOrSet，命中时直接返回T，未命中时调用loader并写入缓存
+// loader出错时不会写入缓存并返回T的零值，同一个key并发未命中时只会调用一次loader
+func Remember[T any](rad *RadCache, key string, exp time.Duration, loader func() (T, error)) (T, error) {
+	result, err := GetValue[T](rad, key)
+	if err == nil {
+		return result, nil
+	}
+	var zero T
+	if !errors.Is(err, ErrCacheMiss) || errors.Is(err, ErrNotFound) {
+		return zero, err
+	}
+	// 按类型区分flightKey，避免与GetOrSet等其他类型的loader合并而拿到类型不同的结果
+	flightKey := rad.Key(key) + "\x00" + reflect.TypeOf((*T)(nil)).Elem().String()
+	val, err := rad.loadShared(flightKey, key, exp, func() (interface{}, error) {
+		v, err := loader()
+		return v, err
+	})
+	if err != nil {
+		return zero, err
+	}
+	if val == nil {
+		return zero, nil
+	}
+	v, ok := val.(T)
+	if !ok {
+		err = fmt.Errorf("radcache: Remember loaded %T, want %T", val, zero)
+		rad.report("Remember", key, err)
+		return zero, err
+	}
+	return v, nil
+}
+
+// 泛型方式批量设置值，所有SET命令通过一个pipeline发送
+func SetMany[T any](rad *RadCache, items map[string]T, exp time.Duration) error {
+	pairs := make(map[string]interface{}, len(items))
+	for k, v := range items {
+		pairs[k] = v
+	}
+	return rad.MSet(pairs, exp)
+}
+
+// 泛型方式批量获取值，返回以原始key为键的map，不存在的key不会出现在结果中
+func GetMany[T any](rad *RadCache, keys []string) (map[string]T, error) {
+	result := make(map[string]T, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+	var prefixed []string
+	for _, v := range keys {
+		prefixed = append(prefixed, rad.Key(v))
+	}
+	values, err := rad.mget(prefixed)
+	if err != nil {
+		rad.report("GetMany", strings.Join(keys, ","), err)
+		return nil, err
+	}
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var val T
+		err := rad.unmarshalInto(str, &val)
+		if errors.Is(err, ErrCacheMiss) {
+			continue
+		}
+		if err != nil {
+			rad.report("GetMany", strings.Join(keys, ","), err)
+			return nil, err
+		}
+		result[keys[i]] = val
+	}
+	return result, nil
+}
+
+// 泛型方式批量获取值，返回的slice与keys按下标一一对应，不存在的key对应nil
+func GetOrdered[T any](rad *RadCache, keys []string) ([]*T, error) {
+	result := make([]*T, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+	prefixed := make([]string, 0, len(keys))
+	for _, v := range keys {
+		prefixed = append(prefixed, rad.Key(v))
+	}
+	values, err := rad.mget(prefixed)
+	if err != nil {
+		rad.report("GetOrdered", strings.Join(keys, ","), err)
+		return nil, err
+	}
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		val := new(T)
+		err := rad.unmarshalInto(str, val)
+		if errors.Is(err, ErrCacheMiss) {
+			continue
+		}
+		if err != nil {
+			rad.report("GetOrdered", strings.Join(keys, ","), err)
+			return nil, err
+		}
+		result[i] = val
+	}
+	return result, nil
+}
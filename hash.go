@@ -0,0 +1,45 @@
+package radcache
+
+// 设置哈希表中指定字段的值，值会被序列化
+func (rad *RadCache) HSet(key, field string, value interface{}) error {
+	val, err := rad.Marshal(value)
+	if err != nil {
+		rad.report("HSet", key, err)
+		return err
+	}
+	err = rad.db().HSet(rad.ctx(), rad.Key(key), field, val).Err()
+	if err != nil {
+		rad.report("HSet", key, err)
+	}
+	return err
+}
+
+// 获取哈希表中指定字段的值，字段不存在时返回ErrCacheMiss
+func (rad *RadCache) HGet(key, field string) (interface{}, error) {
+	result, err := rad.db().HGet(rad.ctx(), rad.Key(key), field).Result()
+	if err != nil {
+		err = missErr(err)
+		rad.report("HGet", key, err)
+		return nil, err
+	}
+	return rad.UnMarshal(result)
+}
+
+// 获取哈希表中所有字段的值，key不存在时返回空的map
+func (rad *RadCache) HGetAll(key string) (map[string]interface{}, error) {
+	values, err := rad.db().HGetAll(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		rad.report("HGetAll", key, err)
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(values))
+	for field, v := range values {
+		val, err := rad.UnMarshal(v)
+		if err != nil {
+			rad.report("HGetAll", key, err)
+			return nil, err
+		}
+		result[field] = val
+	}
+	return result, nil
+}
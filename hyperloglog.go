@@ -0,0 +1,32 @@
+package radcache
+
+import "strings"
+
+// 向HyperLogLog中添加元素，用于估算不重复元素的数量
+func (rad *RadCache) PFAdd(key string, elements ...interface{}) error {
+	vals, err := rad.marshalMembers(elements)
+	if err != nil {
+		rad.report("PFAdd", key, err)
+		return err
+	}
+	err = rad.db().PFAdd(rad.ctx(), rad.Key(key), vals...).Err()
+	if err != nil {
+		rad.report("PFAdd", key, err)
+	}
+	return err
+}
+
+// 估算不重复元素的数量，传入多个key时返回它们并集的估算值
+// 集群模式下多个key需要位于同一个slot
+func (rad *RadCache) PFCount(keys ...string) (int64, error) {
+	var prefixed []string
+	for _, v := range keys {
+		prefixed = append(prefixed, rad.Key(v))
+	}
+	result, err := rad.db().PFCount(rad.ctx(), prefixed...).Result()
+	if err != nil {
+		rad.report("PFCount", strings.Join(keys, ","), err)
+		return 0, err
+	}
+	return result, nil
+}
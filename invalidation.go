@@ -0,0 +1,60 @@
+package radcache
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// 默认的本地缓存失效通知频道
+const DefaultInvalidationChannel = "radcache:invalidate"
+
+// 开启多节点间的本地缓存失效通知，写入或删除key时会向channel发布key，所有开启了通知的实例收到后会移除本地缓存中的key
+// channel为空时使用DefaultInvalidationChannel，频道名不会加上前缀，同一组实例需要使用相同的频道
+// 订阅会在Close时关闭；通知是异步的，其他节点在收到通知前仍可能读到旧值；未配置客户端时返回ErrNoClient
+func (rad *RadCache) UseLocalInvalidation(channel string) error {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+	// 与Subscribe相同，订阅的连接不经过占位客户端，未配置客户端时需要直接拒绝
+	if rad.Db == nil {
+		rad.report("UseLocalInvalidation", channel, ErrNoClient)
+		return ErrNoClient
+	}
+	pubsub := rad.db().Subscribe(rad.ctx(), channel)
+	// 等待订阅确认，以便连接失败时能直接返回错误
+	if _, err := pubsub.Receive(rad.ctx()); err != nil {
+		pubsub.Close()
+		rad.report("UseLocalInvalidation", channel, err)
+		return err
+	}
+	ch := pubsub.Channel()
+	go func() {
+		for msg := range ch {
+			var keys []string
+			if err := json.Unmarshal([]byte(msg.Payload), &keys); err != nil {
+				rad.report("UseLocalInvalidation", channel, err)
+				continue
+			}
+			if rad.local != nil {
+				rad.local.del(keys...)
+			}
+		}
+	}()
+	rad.subs.add(&subscription{pubsub: pubsub, owner: rad.subs})
+	rad.invalidationChannel = channel
+	return nil
+}
+
+// 向其他节点发布key(已包含前缀)失效的通知，未开启通知时不做任何事
+func (rad *RadCache) publishInvalidation(keys ...string) {
+	if rad.invalidationChannel == "" || len(keys) == 0 {
+		return
+	}
+	payload, err := json.Marshal(keys)
+	if err == nil {
+		err = rad.db().Publish(rad.ctx(), rad.invalidationChannel, payload).Err()
+	}
+	if err != nil {
+		rad.report("PublishInvalidation", strings.Join(keys, ","), err)
+	}
+}
@@ -0,0 +1,51 @@
+package radcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// redis未加载RedisJSON模块
+var ErrNoJSONModule = errors.New("radcache: RedisJSON module is not loaded")
+
+// 将未知命令的错误转换为ErrNoJSONModule
+func jsonModuleErr(err error) error {
+	if err != nil && strings.Contains(err.Error(), "unknown command") {
+		return fmt.Errorf("%w: %v", ErrNoJSONModule, err)
+	}
+	return err
+}
+
+// 通过JSON.SET设置json文档中path处的值，value总是使用json序列化，与配置的序列化方式无关
+func (rad *RadCache) JSONSet(key, path string, value interface{}) error {
+	val, err := json.Marshal(value)
+	if err != nil {
+		rad.report("JSONSet", key, err)
+		return err
+	}
+	rad.flushPending(rad.Key(key))
+	err = jsonModuleErr(rad.db().Do(rad.ctx(), "JSON.SET", rad.Key(key), path, string(val)).Err())
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("JSONSet", key, err)
+	}
+	return err
+}
+
+// 通过JSON.GET获取json文档中path处的值，key不存在时返回ErrCacheMiss
+func (rad *RadCache) JSONGet(key, path string) (interface{}, error) {
+	result, err := rad.db().Do(rad.ctx(), "JSON.GET", rad.Key(key), path).Text()
+	if err != nil {
+		err = jsonModuleErr(missErr(err))
+		rad.report("JSONGet", key, err)
+		return nil, err
+	}
+	var val interface{}
+	if err := json.Unmarshal([]byte(result), &val); err != nil {
+		rad.report("JSONGet", key, err)
+		return nil, err
+	}
+	return val, nil
+}
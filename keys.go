@@ -0,0 +1,70 @@
+package radcache
+
+import "time"
+
+// 将oldKey重命名为newKey，newKey已存在时会被覆盖
+func (rad *RadCache) Rename(oldKey, newKey string) error {
+	rad.flushPending(rad.Key(oldKey), rad.Key(newKey))
+	err := rad.db().Rename(rad.ctx(), rad.Key(oldKey), rad.Key(newKey)).Err()
+	rad.invalidate(rad.Key(oldKey), rad.Key(newKey))
+	if err != nil {
+		rad.report("Rename", oldKey, err)
+	}
+	return err
+}
+
+// 仅当newKey不存在时将oldKey重命名为newKey，返回是否重命名成功
+func (rad *RadCache) RenameNX(oldKey, newKey string) (bool, error) {
+	rad.flushPending(rad.Key(oldKey), rad.Key(newKey))
+	result, err := rad.db().RenameNX(rad.ctx(), rad.Key(oldKey), rad.Key(newKey)).Result()
+	rad.invalidate(rad.Key(oldKey), rad.Key(newKey))
+	if err != nil {
+		rad.report("RenameNX", oldKey, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// 获取key保存的数据类型，如string、hash、list、set、zset，key不存在时返回none
+func (rad *RadCache) Type(key string) (string, error) {
+	result, err := rad.db().Type(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		rad.report("Type", key, err)
+		return "", err
+	}
+	return result, nil
+}
+
+// 获取key序列化后的原始数据(Redis DUMP格式)，可通过Restore写入其他Redis实例
+// key不存在时返回ErrCacheMiss
+func (rad *RadCache) Dump(key string) ([]byte, error) {
+	result, err := rad.db().Dump(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		err = missErr(err)
+		rad.report("Dump", key, err)
+		return nil, err
+	}
+	return []byte(result), nil
+}
+
+// 使用Dump获取的数据恢复key，ttl为0时不过期，key已存在时返回错误
+func (rad *RadCache) Restore(key string, ttl time.Duration, data []byte) error {
+	rad.flushPending(rad.Key(key))
+	err := rad.db().Restore(rad.ctx(), rad.Key(key), ttl, string(data)).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("Restore", key, err)
+	}
+	return err
+}
+
+// 获取key占用的内存字节数(近似值)，key不存在时返回ErrCacheMiss
+func (rad *RadCache) MemoryUsage(key string) (int64, error) {
+	result, err := rad.db().MemoryUsage(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		err = missErr(err)
+		rad.report("MemoryUsage", key, err)
+		return 0, err
+	}
+	return result, nil
+}
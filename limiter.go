@@ -0,0 +1,71 @@
+package radcache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 计数加1，仅在计数器新建时设置过期时间，保证INCR和PEXPIRE的原子性
+var fixedWindowScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// 固定窗口限流，window时间内最多允许limit次请求，超过时返回false
+func (rad *RadCache) Allow(key string, limit int, window time.Duration) (bool, error) {
+	n, err := fixedWindowScript.Run(rad.ctx(), rad.db(), []string{rad.Key(key)}, window.Milliseconds()).Int64()
+	if err != nil {
+		rad.report("Allow", key, err)
+		return false, err
+	}
+	return n <= int64(limit), nil
+}
+
+// 移除窗口外的请求记录后计数，未超过限制时记录本次请求，时间单位为微秒(lua中的数字为双精度浮点数，纳秒会丢失精度)
+// 返回 {是否允许(1/0), 剩余次数}
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count >= limit then
+	return {0, 0}
+end
+redis.call("ZADD", KEYS[1], now, ARGV[4])
+redis.call("PEXPIRE", KEYS[1], math.ceil(window / 1000))
+return {1, limit - count - 1}
+`)
+
+// 滑动窗口限流，任意window时间段内最多允许limit次请求，返回是否允许以及剩余的次数
+// 使用有序集合记录每次请求的时间，比固定窗口更平滑，不会在窗口边界出现突发流量
+func (rad *RadCache) AllowSliding(key string, limit int, window time.Duration) (bool, int, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return false, 0, err
+	}
+	now := time.Now().UnixNano() / int64(time.Microsecond)
+	// 成员使用时间加随机数，避免同一时刻的多个请求被合并
+	member := strconv.FormatInt(now, 10) + "-" + hex.EncodeToString(buf)
+	result, err := slidingWindowScript.Run(rad.ctx(), rad.db(), []string{rad.Key(key)},
+		now, window.Microseconds(), limit, member).Result()
+	if err != nil {
+		rad.report("AllowSliding", key, err)
+		return false, 0, err
+	}
+	values, _ := result.([]interface{})
+	if len(values) != 2 {
+		return false, 0, errors.New("radcache: unexpected sliding window result")
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	return allowed == 1, int(remaining), nil
+}
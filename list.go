@@ -0,0 +1,86 @@
+package radcache
+
+import (
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PushCapped的maxLen必须大于0
+var ErrInvalidMaxLen = errors.New("radcache: maxLen must be greater than 0")
+
+// 从列表头部插入一个或多个值，每个值都会被序列化
+func (rad *RadCache) LPush(key string, values ...interface{}) error {
+	vals, err := rad.marshalAll(values)
+	if err != nil {
+		rad.report("LPush", key, err)
+		return err
+	}
+	err = rad.db().LPush(rad.ctx(), rad.Key(key), vals...).Err()
+	if err != nil {
+		rad.report("LPush", key, err)
+	}
+	return err
+}
+
+// 从列表尾部插入一个或多个值，每个值都会被序列化
+func (rad *RadCache) RPush(key string, values ...interface{}) error {
+	vals, err := rad.marshalAll(values)
+	if err != nil {
+		rad.report("RPush", key, err)
+		return err
+	}
+	err = rad.db().RPush(rad.ctx(), rad.Key(key), vals...).Err()
+	if err != nil {
+		rad.report("RPush", key, err)
+	}
+	return err
+}
+
+// 从列表头部插入一个或多个值，并将列表裁剪为最多maxLen个元素，超出的旧元素会被删除
+// LPUSH和LTRIM在同一个事务中执行，适合保存最近N条记录
+func (rad *RadCache) PushCapped(key string, maxLen int64, values ...interface{}) error {
+	if maxLen <= 0 {
+		return ErrInvalidMaxLen
+	}
+	vals, err := rad.marshalAll(values)
+	if err != nil {
+		rad.report("PushCapped", key, err)
+		return err
+	}
+	_, err = rad.db().TxPipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		pipe.LPush(rad.ctx(), rad.Key(key), vals...)
+		pipe.LTrim(rad.ctx(), rad.Key(key), 0, maxLen-1)
+		return nil
+	})
+	if err != nil {
+		rad.report("PushCapped", key, err)
+	}
+	return err
+}
+
+// 从列表头部弹出一个值，列表为空时返回ErrCacheMiss
+func (rad *RadCache) LPop(key string) (interface{}, error) {
+	result, err := rad.db().LPop(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		err = missErr(err)
+		rad.report("LPop", key, err)
+		return nil, err
+	}
+	return rad.UnMarshal(result)
+}
+
+// 获取列表中指定范围的值，start和stop的含义与redis的LRANGE相同
+func (rad *RadCache) LRange(key string, start, stop int64) ([]interface{}, error) {
+	values, err := rad.db().LRange(rad.ctx(), rad.Key(key), start, stop).Result()
+	if err != nil {
+		rad.report("LRange", key, err)
+		return nil, err
+	}
+	result, err := rad.unmarshalAll(values)
+	if err != nil {
+		rad.report("LRange", key, err)
+		return nil, err
+	}
+	return result, nil
+}
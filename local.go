@@ -0,0 +1,132 @@
+package radcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// 进程内的LRU缓存，作为redis前的一级缓存，保存从redis读取的原始字符串
+type localCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type localEntry struct {
+	key      string
+	value    string
+	expireAt time.Time
+}
+
+func newLocalCache(size int, ttl time.Duration) *localCache {
+	return &localCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *localCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*localEntry)
+	if time.Now().After(entry.expireAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *localCache) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expireAt := time.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*localEntry)
+		entry.value = value
+		entry.expireAt = expireAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&localEntry{key: key, value: value, expireAt: expireAt})
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*localEntry).key)
+	}
+}
+
+func (c *localCache) del(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
+
+// 启用进程内的一级缓存，最多保存size个key，每个key在本地最多保存ttl时间，与redis中的过期时间无关
+// size或ttl不大于0时关闭本地缓存
+func (rad *RadCache) UseLocalCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		rad.local = nil
+		return
+	}
+	rad.local = newLocalCache(size, ttl)
+}
+
+// 从本地缓存中移除key(已包含前缀)并通知其他节点，写入或删除redis中的值后需要调用，写入前需要先调用flushPending
+func (rad *RadCache) invalidate(keys ...string) {
+	if rad.local != nil {
+		rad.local.del(keys...)
+	}
+	rad.publishInvalidation(keys...)
+}
+
+// 获取key的原始字符串值并按op记录命中统计和耗时，启用了本地缓存时优先从本地缓存读取，key不存在时返回ErrCacheMiss，值为SetNotFound写入的标记时返回ErrNotFound
+// redis不可用且设置了fallback时从备用缓存读取
+func (rad *RadCache) fetch(op, key string) (string, error) {
+	if err := rad.checkKey(key); err != nil {
+		return "", err
+	}
+	k := rad.Key(key)
+	start := time.Now()
+	if val, ok := rad.wb.get(k); ok {
+		rad.stats.record(op, nil, time.Since(start))
+		return checkNotFound(val)
+	}
+	if rad.local != nil {
+		if val, ok := rad.local.get(k); ok {
+			rad.stats.record(op, nil, time.Since(start))
+			return checkNotFound(val)
+		}
+	}
+	var val string
+	err := rad.retry(func() (err error) {
+		val, err = rad.db().Get(rad.ctx(), k).Result()
+		return err
+	})
+	rad.stats.record(op, err, time.Since(start))
+	if err != nil && rad.fallback != nil && isUnavailable(err) {
+		return rad.fallback.fetch(op, key)
+	}
+	if err != nil {
+		return "", missErr(err)
+	}
+	if rad.local != nil {
+		rad.local.set(k, val)
+	}
+	return checkNotFound(val)
+}
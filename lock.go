@@ -0,0 +1,52 @@
+package radcache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 释放锁时锁已过期或已被其他持有者获取
+var ErrLockNotHeld = errors.New("radcache: lock not held")
+
+// 仅当锁的值仍为自己的token时才删除，避免释放其他持有者的锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// 尝试获取分布式锁，ttl为锁的最长持有时间，防止持有者崩溃后死锁
+// 获取成功时acquired为true，通过unlock释放锁；锁已被占用时acquired为false且unlock为nil
+func (rad *RadCache) Lock(key string, ttl time.Duration) (unlock func() error, acquired bool, err error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(buf)
+	k := rad.Key(key)
+	ok, err := rad.db().SetNX(rad.ctx(), k, token, ttl).Result()
+	if err != nil {
+		rad.report("Lock", key, err)
+		return nil, false, err
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	unlock = func() error {
+		n, err := unlockScript.Run(rad.ctx(), rad.db(), []string{k}, token).Int64()
+		if err != nil {
+			rad.report("Lock", key, err)
+			return err
+		}
+		if n == 0 {
+			return ErrLockNotHeld
+		}
+		return nil
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,31 @@
+package radcache
+
+import "time"
+
+// SetWithMeta保存的值与元数据
+type metaEnvelope struct {
+	Value interface{}       `json:"v" msgpack:"v"`
+	Meta  map[string]string `json:"m,omitempty" msgpack:"m,omitempty"`
+}
+
+// 设置值并附带元数据(如创建时间、数据来源)，值和元数据会保存在同一个key中
+// 需要通过GetWithMeta读取，使用Get读取时得到的是包含值和元数据的结构
+func (rad *RadCache) SetWithMeta(key string, value interface{}, meta map[string]string, exp time.Duration) error {
+	return rad.Set(key, metaEnvelope{Value: value, Meta: meta}, exp).Err()
+}
+
+// 获取SetWithMeta保存的值和元数据，key不存在时返回ErrCacheMiss
+func (rad *RadCache) GetWithMeta(key string) (interface{}, map[string]string, error) {
+	val, err := rad.fetch("GetWithMeta", key)
+	if err != nil {
+		rad.report("GetWithMeta", key, err)
+		return nil, nil, err
+	}
+	var env metaEnvelope
+	if err := rad.unmarshalInto(val, &env); err != nil {
+		rad.dropStale(key, err)
+		rad.report("GetWithMeta", key, err)
+		return nil, nil, err
+	}
+	return env.Value, env.Meta, nil
+}
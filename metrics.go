@@ -0,0 +1,118 @@
+package radcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 缓存命中情况的统计快照
+type Stats struct {
+	// 命中次数
+	Hits int64
+	// 未命中(redis.Nil)次数
+	Misses int64
+	// 出错次数
+	Errors int64
+	// 所有读取操作的累计耗时
+	Duration time.Duration
+	// 按操作(Get、GetString等)统计的读取耗时分布
+	Latency map[string]LatencyStats
+}
+
+// 一种操作的读取耗时分布
+type LatencyStats struct {
+	// 读取次数
+	Count uint64
+	// 累计耗时
+	Sum time.Duration
+	// 以区间上限(秒，从0.0001到1)为键，耗时不超过该上限的累计次数
+	Buckets map[float64]uint64
+}
+
+// 耗时分布的各个区间上限，单位为秒
+var latencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// 使用原子操作计数的统计数据
+type stats struct {
+	hits   int64
+	misses int64
+	errors int64
+	nanos  int64
+	// 操作名到*opLatency
+	latency sync.Map
+}
+
+// 一种操作的耗时分布，counts比latencyBuckets多一个，用于记录超过所有上限的次数
+type opLatency struct {
+	nanos  int64
+	counts []uint64
+}
+
+// 根据读取的结果记录一次命中、未命中或错误，以及op本次读取的耗时
+func (s *stats) record(op string, err error, d time.Duration) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.nanos, int64(d))
+	s.observe(op, d)
+	switch {
+	case err == nil:
+		atomic.AddInt64(&s.hits, 1)
+	case err == redis.Nil:
+		atomic.AddInt64(&s.misses, 1)
+	default:
+		atomic.AddInt64(&s.errors, 1)
+	}
+}
+
+func (s *stats) observe(op string, d time.Duration) {
+	v, ok := s.latency.Load(op)
+	if !ok {
+		v, _ = s.latency.LoadOrStore(op, &opLatency{counts: make([]uint64, len(latencyBuckets)+1)})
+	}
+	l := v.(*opLatency)
+	atomic.AddInt64(&l.nanos, int64(d))
+	i := 0
+	for i < len(latencyBuckets) && d.Seconds() > latencyBuckets[i] {
+		i++
+	}
+	atomic.AddUint64(&l.counts[i], 1)
+}
+
+// 获取当前的命中统计
+func (rad *RadCache) Stats() Stats {
+	s := rad.stats
+	if s == nil {
+		return Stats{}
+	}
+	return Stats{
+		Hits:     atomic.LoadInt64(&s.hits),
+		Misses:   atomic.LoadInt64(&s.misses),
+		Errors:   atomic.LoadInt64(&s.errors),
+		Duration: time.Duration(atomic.LoadInt64(&s.nanos)),
+		Latency:  s.latencyStats(),
+	}
+}
+
+func (s *stats) latencyStats() map[string]LatencyStats {
+	result := make(map[string]LatencyStats)
+	s.latency.Range(func(k, v interface{}) bool {
+		l := v.(*opLatency)
+		ls := LatencyStats{
+			Sum:     time.Duration(atomic.LoadInt64(&l.nanos)),
+			Buckets: make(map[float64]uint64, len(latencyBuckets)),
+		}
+		for i := range l.counts {
+			ls.Count += atomic.LoadUint64(&l.counts[i])
+			if i < len(latencyBuckets) {
+				ls.Buckets[latencyBuckets[i]] = ls.Count
+			}
+		}
+		result[k.(string)] = ls
+		return true
+	})
+	return result
+}
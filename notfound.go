@@ -0,0 +1,33 @@
+package radcache
+
+import "time"
+
+// 缓存中记录了该key对应的数据不存在(由SetNotFound写入)
+// 同时也是一种未命中，errors.Is(err, ErrCacheMiss)同样成立
+var ErrNotFound error = notFoundError{}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string { return "radcache: cached as not found" }
+
+func (notFoundError) Is(target error) bool { return target == ErrCacheMiss }
+
+// 记录key对应的数据不存在，之后Get等方法会返回ErrNotFound，GetOrSet等方法不会再调用loader
+// 用于防止对不存在的数据的重复查询穿透到数据库，exp应设置为较短的时间
+func (rad *RadCache) SetNotFound(key string, exp time.Duration) error {
+	rad.flushPending(rad.Key(key))
+	err := rad.db().Set(rad.ctx(), rad.Key(key), string([]byte{headerMark, flagNotFound}), rad.expiration(exp)).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetNotFound", key, err)
+	}
+	return err
+}
+
+// 读取到的值是SetNotFound写入的标记时返回ErrNotFound，否则原样返回
+func checkNotFound(val string) (string, error) {
+	if len(val) >= 2 && val[0] == headerMark && val[1]&flagNotFound != 0 {
+		return "", ErrNotFound
+	}
+	return val, nil
+}
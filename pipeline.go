@@ -0,0 +1,90 @@
+package radcache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 批量执行命令的管道，命令会先缓存，在Pipeline返回前通过MULTI/EXEC一次性发送
+type RadPipeline struct {
+	rad  *RadCache
+	pipe redis.Pipeliner
+	err  error
+	// 写入过的key，执行后需要从本地缓存中移除
+	keys []string
+}
+
+// 管道中Get命令的结果，Pipeline返回后才能获取
+type PipelineGet struct {
+	rad *RadCache
+	cmd *redis.StringCmd
+}
+
+// 获取反序列化后的值，key不存在时返回ErrCacheMiss
+func (g *PipelineGet) Result() (interface{}, error) {
+	val, err := g.cmd.Result()
+	if err != nil {
+		return nil, missErr(err)
+	}
+	return g.rad.UnMarshal(val)
+}
+
+// 在管道中设置值
+func (p *RadPipeline) Set(key string, value interface{}, exp time.Duration) *redis.StatusCmd {
+	val, err := p.rad.Marshal(value)
+	if err != nil {
+		if p.err == nil {
+			p.err = err
+		}
+		cmd := redis.NewStatusCmd(p.rad.ctx())
+		cmd.SetErr(err)
+		return cmd
+	}
+	p.keys = append(p.keys, p.rad.Key(key))
+	return p.pipe.Set(p.rad.ctx(), p.rad.Key(key), val, p.rad.expiration(exp))
+}
+
+// 在管道中获取值
+func (p *RadPipeline) Get(key string) *PipelineGet {
+	return &PipelineGet{rad: p.rad, cmd: p.pipe.Get(p.rad.ctx(), p.rad.Key(key))}
+}
+
+// 在管道中删除key
+func (p *RadPipeline) Del(key string) *redis.IntCmd {
+	p.keys = append(p.keys, p.rad.Key(key))
+	return p.pipe.Del(p.rad.ctx(), p.rad.Key(key))
+}
+
+// 在管道中将key的值加1
+func (p *RadPipeline) Incr(key string) *redis.IntCmd {
+	p.keys = append(p.keys, p.rad.Key(key))
+	return p.pipe.Incr(p.rad.ctx(), p.rad.Key(key))
+}
+
+// 将fn中添加的命令通过一次往返原子地执行，各命令的结果在返回后可以从对应的返回值中获取
+// 值序列化失败时不会执行任何命令，Get未命中不视为错误
+func (rad *RadCache) Pipeline(fn func(p *RadPipeline)) error {
+	p := &RadPipeline{rad: rad}
+	cmds, err := rad.db().TxPipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		p.pipe = pipe
+		fn(p)
+		rad.flushPending(p.keys...)
+		return p.err
+	})
+	rad.invalidate(p.keys...)
+	if errors.Is(err, redis.Nil) {
+		err = nil
+		for _, cmd := range cmds {
+			if e := cmd.Err(); e != nil && e != redis.Nil {
+				err = e
+				break
+			}
+		}
+	}
+	if err != nil {
+		rad.report("Pipeline", "", err)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package radcache
+
+import (
+	"io"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 记录所有未关闭的订阅，以便Close时统一关闭
+type subscriptions struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+func (s *subscriptions) add(sub *subscription) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[*subscription]struct{})
+	}
+	s.subs[sub] = struct{}{}
+}
+
+func (s *subscriptions) remove(sub *subscription) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+// 关闭所有订阅
+func (s *subscriptions) closeAll() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	subs := s.subs
+	s.subs = nil
+	s.mu.Unlock()
+	for sub := range subs {
+		sub.pubsub.Close()
+	}
+}
+
+// Subscribe返回的订阅，关闭时会从记录中移除
+type subscription struct {
+	pubsub *redis.PubSub
+	owner  *subscriptions
+}
+
+func (sub *subscription) Close() error {
+	sub.owner.remove(sub)
+	return sub.pubsub.Close()
+}
+
+// 订阅频道，频道名会自动加上前缀，收到的消息会在单独的goroutine中交给handler处理
+// 关闭返回的io.Closer或调用Close即可取消订阅并结束该goroutine，未配置客户端时返回ErrNoClient
+func (rad *RadCache) Subscribe(channel string, handler func(payload string)) (io.Closer, error) {
+	// 订阅的连接不经过占位客户端的limiter，未配置客户端时会连接默认地址，需要直接拒绝
+	if rad.Db == nil {
+		rad.report("Subscribe", channel, ErrNoClient)
+		return nil, ErrNoClient
+	}
+	pubsub := rad.db().Subscribe(rad.ctx(), rad.Key(channel))
+	// 等待订阅确认，以便连接失败时能直接返回错误
+	if _, err := pubsub.Receive(rad.ctx()); err != nil {
+		pubsub.Close()
+		rad.report("Subscribe", channel, err)
+		return nil, err
+	}
+	ch := pubsub.Channel()
+	go func() {
+		for msg := range ch {
+			handler(msg.Payload)
+		}
+	}()
+	sub := &subscription{pubsub: pubsub, owner: rad.subs}
+	rad.subs.add(sub)
+	return sub, nil
+}
+
+// 向频道发布消息，频道名会自动加上前缀，string和[]byte原样发送，与Subscribe收到的payload一致
+// 其他类型使用配置的序列化方式序列化(不压缩、不加密)
+func (rad *RadCache) Publish(channel string, message interface{}) error {
+	var val string
+	var err error
+	switch m := message.(type) {
+	case string:
+		val = m
+	case []byte:
+		val = string(m)
+	default:
+		val, err = rad.marshalMember(message)
+	}
+	if err != nil {
+		rad.report("Publish", channel, err)
+		return err
+	}
+	err = rad.db().Publish(rad.ctx(), rad.Key(channel), val).Err()
+	if err != nil {
+		rad.report("Publish", channel, err)
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+// radprom 将radcache的命中统计导出为prometheus指标，单独放在子包中以免所有使用者都依赖prometheus
+package radprom
+
+import (
+	"github.com/MiracleLau/radcache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 提供命中统计的对象，即*radcache.RadCache
+type statsSource interface {
+	Stats() radcache.Stats
+}
+
+type collector struct {
+	rad      statsSource
+	hits     *prometheus.Desc
+	misses   *prometheus.Desc
+	errors   *prometheus.Desc
+	duration *prometheus.Desc
+	latency  *prometheus.Desc
+}
+
+// 创建一个导出rad命中统计的prometheus.Collector，指标会带上prefix标签以区分多个实例
+// 读取耗时按操作导出为带op标签的直方图
+func NewCollector(rad *radcache.RadCache) prometheus.Collector {
+	return newCollector(rad, rad.Options.Prefix)
+}
+
+func newCollector(rad statsSource, prefix string) *collector {
+	labels := prometheus.Labels{"prefix": prefix}
+	return &collector{
+		rad: rad,
+		hits: prometheus.NewDesc("radcache_hits_total",
+			"Number of cache lookups that found a value.", nil, labels),
+		misses: prometheus.NewDesc("radcache_misses_total",
+			"Number of cache lookups that found no value.", nil, labels),
+		errors: prometheus.NewDesc("radcache_errors_total",
+			"Number of cache lookups that failed with an error.", nil, labels),
+		duration: prometheus.NewDesc("radcache_lookup_duration_seconds_total",
+			"Total time spent in cache lookups.", nil, labels),
+		latency: prometheus.NewDesc("radcache_lookup_duration_seconds",
+			"Latency of cache lookups by operation.", []string{"op"}, labels),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.errors
+	ch <- c.duration
+	ch <- c.latency
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.rad.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(s.Errors))
+	ch <- prometheus.MustNewConstMetric(c.duration, prometheus.CounterValue, s.Duration.Seconds())
+	for op, l := range s.Latency {
+		ch <- prometheus.MustNewConstHistogram(c.latency, l.Count, l.Sum.Seconds(), l.Buckets, op)
+	}
+}
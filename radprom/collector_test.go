@@ -0,0 +1,54 @@
+package radprom
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiracleLau/radcache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fixedStats radcache.Stats
+
+func (s fixedStats) Stats() radcache.Stats { return radcache.Stats(s) }
+
+func TestCollector(t *testing.T) {
+	c := newCollector(fixedStats{
+		Hits:     3,
+		Misses:   1,
+		Duration: 1500 * time.Millisecond,
+		Latency: map[string]radcache.LatencyStats{
+			"Get": {Count: 4, Sum: 1500 * time.Millisecond, Buckets: map[float64]uint64{0.001: 1, 0.01: 3, 1: 3}},
+		},
+	}, "app_")
+	expected := `
+# HELP radcache_hits_total Number of cache lookups that found a value.
+# TYPE radcache_hits_total counter
+radcache_hits_total{prefix="app_"} 3
+# HELP radcache_lookup_duration_seconds Latency of cache lookups by operation.
+# TYPE radcache_lookup_duration_seconds histogram
+radcache_lookup_duration_seconds_bucket{op="Get",prefix="app_",le="0.001"} 1
+radcache_lookup_duration_seconds_bucket{op="Get",prefix="app_",le="0.01"} 3
+radcache_lookup_duration_seconds_bucket{op="Get",prefix="app_",le="1"} 3
+radcache_lookup_duration_seconds_bucket{op="Get",prefix="app_",le="+Inf"} 4
+radcache_lookup_duration_seconds_sum{op="Get",prefix="app_"} 1.5
+radcache_lookup_duration_seconds_count{op="Get",prefix="app_"} 4
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"radcache_hits_total", "radcache_lookup_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorRecordsLookups(t *testing.T) {
+	rad := radcache.NewDefault()
+	rad.Get("a")
+	rad.GetString("b")
+	rad.GetString("c")
+	// 没有客户端时读取都会失败，只检查每种操作都导出了直方图
+	if n := testutil.CollectAndCount(NewCollector(rad), "radcache_lookup_duration_seconds"); n != 2 {
+		t.Fatalf("collected %d histograms, want 2", n)
+	}
+}
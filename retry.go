@@ -0,0 +1,48 @@
+package radcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// 判断错误是否为网络中断、redis重启等临时性错误，redis.Nil等正常结果不会重试
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	// redis正在加载数据，通常出现在重启之后
+	return strings.HasPrefix(err.Error(), "LOADING ")
+}
+
+// 执行fn，遇到临时性错误时按Options.MaxRetries和Options.RetryBackoff进行指数退避重试
+// context被取消或剩余时间不足以等待下一次重试时立即返回
+func (rad *RadCache) retry(fn func() error) error {
+	err := fn()
+	backoff := rad.Options.RetryBackoff
+	for i := 0; i < rad.Options.MaxRetries && isRetryable(err); i++ {
+		if deadline, ok := rad.Ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-rad.Ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package radcache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 默认的SCAN每批数量
+const defaultScanCount = 100
+
+func (rad *RadCache) scanCount() int64 {
+	if rad.Options.ScanCount > 0 {
+		return rad.Options.ScanCount
+	}
+	return defaultScanCount
+}
+
+// 使用SCAN遍历匹配match(已包含前缀)的key，每批调用一次fn，fn返回错误时停止遍历
+// 集群模式下会依次遍历每个master节点
+func (rad *RadCache) scanBatches(match string, fn func(keys []string) error) error {
+	cluster, ok := rad.Db.(*redis.ClusterClient)
+	if !ok {
+		return rad.scanNode(rad.db(), match, fn)
+	}
+	var mu sync.Mutex
+	var nodes []*redis.Client
+	err := cluster.ForEachMaster(rad.ctx(), func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		nodes = append(nodes, node)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := rad.scanNode(node, match, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 在单个节点上使用SCAN遍历
+func (rad *RadCache) scanNode(node redis.Cmdable, match string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := node.Scan(rad.ctx(), cursor, match, rad.scanCount()).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// 遍历所有匹配match的key，match会自动加上前缀，传给fn的key已去掉前缀
+// fn返回错误时停止遍历并返回该错误
+func (rad *RadCache) Scan(match string, fn func(key string) error) error {
+	var fnErr error
+	err := rad.scanBatches(rad.Key(match), func(keys []string) error {
+		for _, k := range keys {
+			if fnErr = fn(strings.TrimPrefix(k, rad.Options.Prefix)); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	})
+	if err != nil && err != fnErr {
+		rad.report("Scan", match, err)
+	}
+	return err
+}
+
+// 获取所有匹配match的key，match会自动加上前缀，返回的key已去掉前缀
+// 内部使用SCAN遍历，但会将所有结果保存在内存中，key数量很多时请使用Scan
+func (rad *RadCache) Keys(match string) ([]string, error) {
+	var keys []string
+	err := rad.Scan(match, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// 统计匹配pattern的key数量，pattern会自动加上前缀，每批大小与Scan相同由ScanCount控制
+// 只计数不保存key；SCAN遍历期间key发生变化时同一个key可能被重复计数，结果为近似值
+func (rad *RadCache) CountByPattern(pattern string) (int64, error) {
+	var count int64
+	err := rad.scanBatches(rad.Key(pattern), func(keys []string) error {
+		count += int64(len(keys))
+		return nil
+	})
+	if err != nil {
+		rad.report("CountByPattern", pattern, err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// 删除所有匹配pattern的key，pattern会自动加上前缀，返回删除的数量
+// 使用SCAN分批遍历，不会使用阻塞redis的KEYS命令
+func (rad *RadCache) DelByPattern(pattern string) (int64, error) {
+	var deleted int64
+	err := rad.scanBatches(rad.Key(pattern), func(keys []string) error {
+		rad.flushPending(keys...)
+		cmds, err := rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+			for _, k := range keys {
+				pipe.Del(rad.ctx(), k)
+			}
+			return nil
+		})
+		rad.invalidate(keys...)
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			deleted += cmd.(*redis.IntCmd).Val()
+		}
+		return nil
+	})
+	if err != nil {
+		rad.report("DelByPattern", pattern, err)
+	}
+	return deleted, err
+}
+
+// 前缀为空时调用Flush会删除整个数据库中的key，因此直接拒绝
+var ErrEmptyPrefix = errors.New("radcache: refusing to flush with an empty prefix")
+
+// 删除当前前缀下的所有key，返回删除的数量
+// 通过SCAN和DEL实现，不会使用FLUSHDB/FLUSHALL，不影响共享同一个redis的其他应用
+func (rad *RadCache) Flush() (int64, error) {
+	if rad.Options.Prefix == "" {
+		return 0, ErrEmptyPrefix
+	}
+	return rad.DelByPattern("*")
+}
@@ -0,0 +1,20 @@
+package radcache
+
+import "errors"
+
+// 值的schema版本与Options.SchemaVersion不一致，同时也是一种未命中，errors.Is(err, ErrCacheMiss)同样成立
+var ErrSchemaMismatch error = schemaMismatchError{}
+
+type schemaMismatchError struct{}
+
+func (schemaMismatchError) Error() string { return "radcache: schema version mismatch" }
+
+func (schemaMismatchError) Is(target error) bool { return target == ErrCacheMiss }
+
+// 配置了DeleteStaleSchema时删除版本不一致的key
+func (rad *RadCache) dropStale(key string, err error) {
+	if !rad.Options.DeleteStaleSchema || !errors.Is(err, ErrSchemaMismatch) {
+		return
+	}
+	rad.Del(key)
+}
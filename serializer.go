@@ -0,0 +1,97 @@
+package radcache
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// 序列化接口，用于将值编码后写入redis以及从redis中读取后解码
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 基于encoding/json的序列化，是默认的序列化方式
+type JSONSerializer struct{}
+
+func (JSONSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONSerializer) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// 基于msgpack的序列化，编码结果比json更小、速度更快，适合较大的结构体
+type MsgpackSerializer struct{}
+
+func (MsgpackSerializer) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (MsgpackSerializer) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// 使用Options中指定的函数进行序列化，未指定的函数使用encoding/json
+type funcSerializer struct {
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+}
+
+func (f funcSerializer) Marshal(v interface{}) ([]byte, error) {
+	if f.marshal == nil {
+		return json.Marshal(v)
+	}
+	return f.marshal(v)
+}
+
+func (f funcSerializer) Unmarshal(data []byte, v interface{}) error {
+	if f.unmarshal == nil {
+		return json.Unmarshal(data, v)
+	}
+	return f.unmarshal(data, v)
+}
+
+// 获取当前使用的序列化方式，优先使用UseSerializer指定的方式，其次为Options中的序列化函数，都未指定时使用json
+func (rad *RadCache) serializer() Serializer {
+	if rad.Serializer != nil {
+		return rad.Serializer
+	}
+	if rad.Options.MarshalFunc != nil || rad.Options.UnmarshalFunc != nil {
+		return funcSerializer{marshal: rad.Options.MarshalFunc, unmarshal: rad.Options.UnmarshalFunc}
+	}
+	return JSONSerializer{}
+}
+
+// 将字符串反序列化到dest中，压缩过的值会先解压
+func (rad *RadCache) unmarshalInto(val string, dest interface{}) error {
+	data, err := rad.unpack([]byte(val))
+	if err != nil {
+		return err
+	}
+	return rad.serializer().Unmarshal(data, dest)
+}
+
+// 序列化集合成员等需要按值比较的数据，不会进行压缩和加密，保证相同的值序列化结果相同
+func (rad *RadCache) marshalMember(v interface{}) (string, error) {
+	re, err := rad.serializer().Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(re), nil
+}
+
+// 依次序列化多个成员
+func (rad *RadCache) marshalMembers(values []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		val, err := rad.marshalMember(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package radcache
+
+import "testing"
+
+type benchItem struct {
+	ID    int               `json:"id" msgpack:"id"`
+	Name  string            `json:"name" msgpack:"name"`
+	Tags  []string          `json:"tags" msgpack:"tags"`
+	Attrs map[string]string `json:"attrs" msgpack:"attrs"`
+}
+
+type benchOrder struct {
+	ID    int64       `json:"id" msgpack:"id"`
+	User  string      `json:"user" msgpack:"user"`
+	Total float64     `json:"total" msgpack:"total"`
+	Items []benchItem `json:"items" msgpack:"items"`
+}
+
+func newBenchOrder() benchOrder {
+	order := benchOrder{ID: 42, User: "user:1", Total: 99.5}
+	for i := 0; i < 10; i++ {
+		order.Items = append(order.Items, benchItem{
+			ID:    i,
+			Name:  "item",
+			Tags:  []string{"a", "b", "c"},
+			Attrs: map[string]string{"color": "red", "size": "L"},
+		})
+	}
+	return order
+}
+
+func benchmarkSerializer(b *testing.B, s Serializer) {
+	order := newBenchOrder()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := s.Marshal(order)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var out benchOrder
+		if err := s.Unmarshal(data, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONSerializer(b *testing.B) {
+	benchmarkSerializer(b, JSONSerializer{})
+}
+
+func BenchmarkMsgpackSerializer(b *testing.B) {
+	benchmarkSerializer(b, MsgpackSerializer{})
+}
@@ -0,0 +1,74 @@
+package radcache
+
+// 向集合中添加一个或多个成员
+func (rad *RadCache) SAdd(key string, members ...interface{}) error {
+	vals, err := rad.marshalMembers(members)
+	if err != nil {
+		rad.report("SAdd", key, err)
+		return err
+	}
+	err = rad.db().SAdd(rad.ctx(), rad.Key(key), vals...).Err()
+	if err != nil {
+		rad.report("SAdd", key, err)
+	}
+	return err
+}
+
+// 向集合中添加一个成员，仅当成员之前不在集合中时返回true，可用于保证每个成员只处理一次
+func (rad *RadCache) AddIfNew(setKey string, member interface{}) (bool, error) {
+	val, err := rad.marshalMember(member)
+	if err != nil {
+		rad.report("AddIfNew", setKey, err)
+		return false, err
+	}
+	added, err := rad.db().SAdd(rad.ctx(), rad.Key(setKey), val).Result()
+	if err != nil {
+		rad.report("AddIfNew", setKey, err)
+		return false, err
+	}
+	return added == 1, nil
+}
+
+// 获取集合中的所有成员
+func (rad *RadCache) SMembers(key string) ([]interface{}, error) {
+	values, err := rad.db().SMembers(rad.ctx(), rad.Key(key)).Result()
+	if err != nil {
+		rad.report("SMembers", key, err)
+		return nil, err
+	}
+	result, err := rad.unmarshalAll(values)
+	if err != nil {
+		rad.report("SMembers", key, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// 判断member是否在集合中
+func (rad *RadCache) SIsMember(key string, member interface{}) (bool, error) {
+	val, err := rad.marshalMember(member)
+	if err != nil {
+		rad.report("SIsMember", key, err)
+		return false, err
+	}
+	result, err := rad.db().SIsMember(rad.ctx(), rad.Key(key), val).Result()
+	if err != nil {
+		rad.report("SIsMember", key, err)
+		return false, err
+	}
+	return result, nil
+}
+
+// 从集合中移除一个或多个成员
+func (rad *RadCache) SRem(key string, members ...interface{}) error {
+	vals, err := rad.marshalMembers(members)
+	if err != nil {
+		rad.report("SRem", key, err)
+		return err
+	}
+	err = rad.db().SRem(rad.ctx(), rad.Key(key), vals...).Err()
+	if err != nil {
+		rad.report("SRem", key, err)
+	}
+	return err
+}
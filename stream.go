@@ -0,0 +1,65 @@
+package radcache
+
+import "github.com/go-redis/redis/v8"
+
+// 流中的一条消息
+type StreamEntry struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// 向流中添加一条消息，每个字段的值都会被序列化，返回redis生成的消息ID
+func (rad *RadCache) XAdd(stream string, values map[string]interface{}) (string, error) {
+	fields := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		val, err := rad.Marshal(v)
+		if err != nil {
+			rad.report("XAdd", stream, err)
+			return "", err
+		}
+		fields[k] = val
+	}
+	id, err := rad.db().XAdd(rad.ctx(), &redis.XAddArgs{
+		Stream: rad.Key(stream),
+		Values: fields,
+	}).Result()
+	if err != nil {
+		rad.report("XAdd", stream, err)
+		return "", err
+	}
+	return id, nil
+}
+
+// 读取流中ID大于lastID的最多count条消息，不会阻塞，没有新消息时返回空的切片
+// lastID为"0"时从头开始读取
+func (rad *RadCache) XRead(stream, lastID string, count int64) ([]StreamEntry, error) {
+	streams, err := rad.db().XRead(rad.ctx(), &redis.XReadArgs{
+		Streams: []string{rad.Key(stream), lastID},
+		Count:   count,
+		Block:   -1,
+	}).Result()
+	if err == redis.Nil {
+		return []StreamEntry{}, nil
+	}
+	if err != nil {
+		rad.report("XRead", stream, err)
+		return nil, err
+	}
+	var entries []StreamEntry
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			values := make(map[string]interface{}, len(msg.Values))
+			for k, v := range msg.Values {
+				str, _ := v.(string)
+				val, err := rad.UnMarshal(str)
+				if err != nil {
+					rad.report("XRead", stream, err)
+					return nil, err
+				}
+				values[k] = val
+			}
+			entries = append(entries, StreamEntry{ID: msg.ID, Values: values})
+		}
+	}
+	return entries, nil
+}
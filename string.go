@@ -0,0 +1,58 @@
+package radcache
+
+import "time"
+
+// 在字符串值的末尾追加内容，key不存在时等同于设置值，返回追加后的长度
+func (rad *RadCache) Append(key, value string) (int64, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().Append(rad.ctx(), rad.Key(key), value).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("Append", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 获取字符串值中[start, end]范围内的子串，负数表示从末尾开始计算
+func (rad *RadCache) GetRange(key string, start, end int64) (string, error) {
+	result, err := rad.db().GetRange(rad.ctx(), rad.Key(key), start, end).Result()
+	if err != nil {
+		rad.report("GetRange", key, err)
+		return "", err
+	}
+	return result, nil
+}
+
+// 从offset处开始覆盖字符串值的内容，返回修改后的长度
+func (rad *RadCache) SetRange(key string, offset int64, value string) (int64, error) {
+	rad.flushPending(rad.Key(key))
+	result, err := rad.db().SetRange(rad.ctx(), rad.Key(key), offset, value).Result()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetRange", key, err)
+		return 0, err
+	}
+	return result, nil
+}
+
+// 直接保存字节数据，不进行序列化、压缩和加密，适合protobuf、图片等已编码的数据
+func (rad *RadCache) SetBytes(key string, data []byte, exp time.Duration) error {
+	rad.flushPending(rad.Key(key))
+	err := rad.db().Set(rad.ctx(), rad.Key(key), data, rad.expiration(exp)).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetBytes", key, err)
+	}
+	return err
+}
+
+// 获取SetBytes保存的字节数据，key不存在时返回ErrCacheMiss，被SetNotFound记录为不存在时返回ErrNotFound
+func (rad *RadCache) GetBytes(key string) ([]byte, error) {
+	val, err := rad.fetch("GetBytes", key)
+	if err != nil {
+		rad.report("GetBytes", key, err)
+		return nil, err
+	}
+	return []byte(val), nil
+}
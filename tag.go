@@ -0,0 +1,95 @@
+package radcache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tag集合名称中前缀之后的部分，以\x00开头以免与普通的key冲突，以\x00tag开头的key为保留的名称
+const tagNamespace = "\x00tag"
+
+// 获取保存tag下所有key的集合的名称
+func (rad *RadCache) tagKey(tag string) string {
+	return rad.Key(tagNamespace, tag)
+}
+
+// 将key加入tag集合，并保证集合的过期时间不短于该key的过期时间(毫秒)，key不过期时集合也不再过期
+var tagAddScript = redis.NewScript(`
+local existed = redis.call("EXISTS", KEYS[1])
+redis.call("SADD", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call("PERSIST", KEYS[1])
+	return 1
+end
+local cur = redis.call("PTTL", KEYS[1])
+if existed == 0 or (cur >= 0 and cur < ttl) then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return 1
+`)
+
+// 设置值并将key记录到每个tag对应的集合中，之后可以通过InvalidateTag批量删除
+// tag集合的过期时间不短于其中最晚过期的key，会在InvalidateTag时删除
+func (rad *RadCache) SetWithTags(key string, value interface{}, exp time.Duration, tags ...string) error {
+	val, err := rad.Marshal(value)
+	if err != nil {
+		rad.report("SetWithTags", key, err)
+		return err
+	}
+	k := rad.Key(key)
+	ttl := rad.expiration(exp)
+	rad.flushPending(k)
+	_, err = rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		pipe.Set(rad.ctx(), k, val, ttl)
+		for _, tag := range tags {
+			// 在pipeline中无法处理NOSCRIPT，直接使用EVAL
+			tagAddScript.Eval(rad.ctx(), pipe, []string{rad.tagKey(tag)}, k, ttl.Milliseconds())
+		}
+		return nil
+	})
+	rad.invalidate(k)
+	if err != nil {
+		rad.report("SetWithTags", key, err)
+	}
+	return err
+}
+
+// 删除tag下的所有key以及tag集合本身
+func (rad *RadCache) InvalidateTag(tag string) error {
+	tk := rad.tagKey(tag)
+	keys, err := rad.db().SMembers(rad.ctx(), tk).Result()
+	if err != nil {
+		rad.report("InvalidateTag", tag, err)
+		return err
+	}
+	rad.flushPending(keys...)
+	_, err = rad.db().Pipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+		for _, k := range keys {
+			pipe.Del(rad.ctx(), k)
+		}
+		pipe.Del(rad.ctx(), tk)
+		return nil
+	})
+	rad.invalidate(keys...)
+	if err != nil {
+		rad.report("InvalidateTag", tag, err)
+	}
+	return err
+}
+
+// 获取tag下记录的所有key，返回的key已去掉前缀，可在InvalidateTag前确认将被删除的key
+// 集合中可能包含已过期的key
+func (rad *RadCache) TagKeys(tag string) ([]string, error) {
+	keys, err := rad.db().SMembers(rad.ctx(), rad.tagKey(tag)).Result()
+	if err != nil {
+		rad.report("TagKeys", tag, err)
+		return nil, err
+	}
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, rad.Options.Prefix)
+	}
+	return keys, nil
+}
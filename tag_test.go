@@ -0,0 +1,17 @@
+package radcache
+
+import "testing"
+
+func TestTagKeyReserved(t *testing.T) {
+	rad := NewDefault()
+	for _, key := range []string{"tag:foo", "tag", "foo"} {
+		if rad.Key(key) == rad.tagKey("foo") {
+			t.Fatalf("key %q collides with the tag set of foo", key)
+		}
+	}
+	// 开启RejectControlChars后无法写入tag集合使用的名称
+	rad.Options.RejectControlChars = true
+	if err := rad.checkKey(tagNamespace + ":foo"); err == nil {
+		t.Fatal("checkKey accepted a key in the tag namespace")
+	}
+}
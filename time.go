@@ -0,0 +1,56 @@
+package radcache
+
+import (
+	"strconv"
+	"time"
+)
+
+// 以RFC3339Nano格式保存时间，保留纳秒精度和时区偏移
+func (rad *RadCache) SetTime(key string, t time.Time, exp time.Duration) error {
+	rad.flushPending(rad.Key(key))
+	err := rad.db().Set(rad.ctx(), rad.Key(key), t.Format(time.RFC3339Nano), rad.expiration(exp)).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetTime", key, err)
+	}
+	return err
+}
+
+// 获取SetTime保存的时间，key不存在时返回ErrCacheMiss
+func (rad *RadCache) GetTime(key string) (time.Time, error) {
+	val, err := rad.fetch("GetTime", key)
+	var result time.Time
+	if err == nil {
+		result, err = time.Parse(time.RFC3339Nano, val)
+	}
+	if err != nil {
+		rad.report("GetTime", key, err)
+		return time.Time{}, err
+	}
+	return result, nil
+}
+
+// 以纳秒数保存时长
+func (rad *RadCache) SetDuration(key string, d time.Duration, exp time.Duration) error {
+	rad.flushPending(rad.Key(key))
+	err := rad.db().Set(rad.ctx(), rad.Key(key), int64(d), rad.expiration(exp)).Err()
+	rad.invalidate(rad.Key(key))
+	if err != nil {
+		rad.report("SetDuration", key, err)
+	}
+	return err
+}
+
+// 获取SetDuration保存的时长，key不存在时返回ErrCacheMiss
+func (rad *RadCache) GetDuration(key string) (time.Duration, error) {
+	val, err := rad.fetch("GetDuration", key)
+	var result int64
+	if err == nil {
+		result, err = strconv.ParseInt(val, 10, 64)
+	}
+	if err != nil {
+		rad.report("GetDuration", key, err)
+		return 0, err
+	}
+	return time.Duration(result), nil
+}
@@ -0,0 +1,145 @@
+package radcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type hookCallKey struct{}
+
+type hookSettingsKey struct{}
+
+// 每次调用时通过context传给hook的配置，超时和熔断只对设置了对应Options的实例生效，不影响共用客户端的其他实例
+type hookSettings struct {
+	timeout   time.Duration
+	breaker   *circuitBreaker
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+}
+
+// 执行命令时使用的context，配置了OpTimeout或BreakerThreshold时附带hook需要的配置
+func (rad *RadCache) ctx() context.Context {
+	ctx := rad.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	threshold := rad.Options.BreakerThreshold
+	if rad.breaker == nil {
+		threshold = 0
+	}
+	if rad.Options.OpTimeout <= 0 && threshold <= 0 {
+		return ctx
+	}
+	cooldown := rad.Options.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return context.WithValue(ctx, hookSettingsKey{}, &hookSettings{
+		timeout:   rad.Options.OpTimeout,
+		breaker:   rad.breaker,
+		threshold: threshold,
+		window:    rad.Options.BreakerWindow,
+		cooldown:  cooldown,
+	})
+}
+
+// 按context中的配置为命令(pipeline整体算一次)加上熔断和超时，超时后命令返回context.DeadlineExceeded
+// 在UseRedis和UseUniversalClient时添加，context中没有配置时不做任何处理
+type radHook struct{}
+
+// 一次命令的hook状态，同一个客户端被多次添加hook时只由第一个hook处理
+type hookCall struct {
+	settings *hookSettings
+	// 设置了超时时派生的context
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   bool
+}
+
+func (radHook) before(ctx context.Context) (context.Context, error) {
+	if ctx.Value(hookCallKey{}) != nil {
+		return ctx, nil
+	}
+	s, _ := ctx.Value(hookSettingsKey{}).(*hookSettings)
+	if s == nil {
+		return ctx, nil
+	}
+	if s.threshold > 0 {
+		if err := s.breaker.allow(); err != nil {
+			return ctx, err
+		}
+	}
+	call := &hookCall{settings: s}
+	if s.timeout > 0 {
+		call.ctx, call.cancel = context.WithTimeout(ctx, s.timeout)
+		ctx = call.ctx
+	}
+	return context.WithValue(ctx, hookCallKey{}, call), nil
+}
+
+// 结束一次命令，命令因超时失败时返回context.DeadlineExceeded，否则返回nil
+func (radHook) after(ctx context.Context, err error) error {
+	call, _ := ctx.Value(hookCallKey{}).(*hookCall)
+	if call == nil || call.done {
+		return nil
+	}
+	call.done = true
+	if call.cancel != nil {
+		// 超时的命令返回的是连接的i/o timeout，需要在取消前判断
+		if err != nil && expired(call.ctx) {
+			err = context.DeadlineExceeded
+		}
+		call.cancel()
+	}
+	if call.settings.threshold > 0 {
+		call.settings.breaker.report(err, call.settings.threshold, call.settings.window, call.settings.cooldown)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return nil
+}
+
+// context是否已超时，连接的读写超时和context的定时器可能有细微的先后差异，因此同时比较截止时间
+func expired(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
+
+func (h radHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.before(ctx)
+}
+
+func (h radHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return h.after(ctx, cmd.Err())
+}
+
+func (h radHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return h.before(ctx)
+}
+
+// 超时时整个pipeline都返回context.DeadlineExceeded
+func (h radHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if errors.Is(cmd.Err(), ErrCircuitOpen) || isBreakerFailure(cmd.Err()) {
+			err = cmd.Err()
+			break
+		}
+	}
+	if err = h.after(ctx, err); err != nil {
+		for _, cmd := range cmds {
+			if cmd.Err() != nil {
+				cmd.SetErr(err)
+			}
+		}
+	}
+	return err
+}
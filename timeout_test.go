@@ -0,0 +1,63 @@
+package radcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 接受连接但从不回复的服务端
+func hangingServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conns []net.Conn
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		<-done
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	return ln.Addr().String()
+}
+
+func TestOpTimeout(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: hangingServer(t), MaxRetries: -1})
+	defer client.Close()
+	rad := New(Options{OpTimeout: 50 * time.Millisecond})
+	rad.UseRedis(client)
+	// 共用客户端的实例会再次添加hook，不能重复计算超时
+	other := New(Options{})
+	other.UseRedis(client)
+
+	start := time.Now()
+	_, err := rad.GetInt("k")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetInt error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("GetInt took %v, want about 50ms", elapsed)
+	}
+	// pipeline超时时同样返回context.DeadlineExceeded
+	if err := rad.SetWithTags("k", 1, time.Minute, "t"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SetWithTags error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,83 @@
+package radcache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Watch中使用的事务，Get会立即读取，Set会在fn返回后通过MULTI/EXEC统一提交
+type RadTx struct {
+	rad  *RadCache
+	tx   *redis.Tx
+	sets []txSet
+}
+
+type txSet struct {
+	key string
+	val string
+	exp time.Duration
+}
+
+// 在事务中读取值，key不存在时返回ErrCacheMiss
+func (t *RadTx) Get(key string) (interface{}, error) {
+	result, err := t.tx.Get(t.rad.ctx(), t.rad.Key(key)).Result()
+	if err != nil {
+		return nil, missErr(err)
+	}
+	return t.rad.UnMarshal(result)
+}
+
+// 在事务中设置值，值会在fn成功返回后提交，被监视的key在此期间被修改时提交失败
+func (t *RadTx) Set(key string, value interface{}, exp time.Duration) error {
+	val, err := t.rad.Marshal(value)
+	if err != nil {
+		return err
+	}
+	t.sets = append(t.sets, txSet{key: t.rad.Key(key), val: val, exp: t.rad.expiration(exp)})
+	return nil
+}
+
+// 监视keys并执行fn，fn中通过tx读取和设置值，实现基于乐观锁的读-改-写
+// 被监视的key在提交前被其他客户端修改时，会重新执行fn，最多重试Options.TxRetries次
+func (rad *RadCache) Watch(fn func(tx *RadTx) error, keys ...string) error {
+	prefixed := make([]string, 0, len(keys))
+	for _, k := range keys {
+		prefixed = append(prefixed, rad.Key(k))
+	}
+	var fnErr error
+	var written []string
+	var err error
+	rad.flushPending(prefixed...)
+	for i := 0; i <= rad.Options.TxRetries; i++ {
+		err = rad.db().Watch(rad.ctx(), func(tx *redis.Tx) error {
+			t := &RadTx{rad: rad, tx: tx}
+			if fnErr = fn(t); fnErr != nil {
+				return fnErr
+			}
+			if len(t.sets) == 0 {
+				return nil
+			}
+			for _, s := range t.sets {
+				rad.flushPending(s.key)
+			}
+			_, err := tx.TxPipelined(rad.ctx(), func(pipe redis.Pipeliner) error {
+				for _, s := range t.sets {
+					pipe.Set(rad.ctx(), s.key, s.val, s.exp)
+					written = append(written, s.key)
+				}
+				return nil
+			})
+			return err
+		}, prefixed...)
+		if err != redis.TxFailedErr {
+			break
+		}
+	}
+	rad.invalidate(written...)
+	if err != nil && err != fnErr {
+		rad.report("Watch", strings.Join(keys, ","), err)
+	}
+	return err
+}
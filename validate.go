@@ -0,0 +1,28 @@
+package radcache
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// key超过Options.MaxKeyLength或包含控制字符，返回的错误包含具体原因，可以通过errors.Is判断
+var ErrInvalidKey = errors.New("radcache: invalid key")
+
+// 按Options中的配置校验key，未开启校验时总是返回nil
+// MaxKeyLength按包含前缀的完整key的字节数计算
+func (rad *RadCache) checkKey(key string) error {
+	if max := rad.Options.MaxKeyLength; max > 0 {
+		if n := len(rad.Key(key)); n > max {
+			return fmt.Errorf("%w: length %d exceeds limit of %d", ErrInvalidKey, n, max)
+		}
+	}
+	if rad.Options.RejectControlChars {
+		for _, r := range key {
+			if unicode.IsControl(r) {
+				return fmt.Errorf("%w: contains control character %q", ErrInvalidKey, r)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package radcache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckKey(t *testing.T) {
+	prefix := NewDefault().Key("")
+	tests := []struct {
+		name          string
+		maxLength     int
+		rejectControl bool
+		key           string
+		wantErr       bool
+	}{
+		{"no limits", 0, false, strings.Repeat("k", 1000) + "\n", false},
+		{"at limit", len(prefix) + 3, false, "abc", false},
+		{"limit includes prefix", len(prefix) + 3, false, "abcd", true},
+		{"multibyte counted in bytes", len(prefix) + 3, false, "键", false},
+		{"multibyte over limit", len(prefix) + 3, false, "键k", true},
+		{"control allowed by default", 0, false, "a\tb", false},
+		{"control rejected", 0, true, "a\tb", true},
+		{"null rejected", 0, true, "a\x00b", true},
+		{"printable accepted", 0, true, "user:1 名字", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rad := NewDefault()
+			rad.Options.MaxKeyLength = tt.maxLength
+			rad.Options.RejectControlChars = tt.rejectControl
+			err := rad.checkKey(tt.key)
+			if tt.wantErr && !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("checkKey(%q) = %v, want ErrInvalidKey", tt.key, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("checkKey(%q) = %v, want nil", tt.key, err)
+			}
+		})
+	}
+}
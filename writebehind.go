@@ -0,0 +1,229 @@
+package radcache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 写缓冲，Set和Incr系列操作先保存在内存中，定时通过pipeline批量写入redis
+type writeBehind struct {
+	mu sync.Mutex
+	// 待写入的值，key已包含前缀
+	sets map[string]pendingSet
+	// 待写入的计数器增量，key已包含前缀
+	counters map[string]*pendingCounter
+	// 正在写入redis的数据，写入完成前读取仍需要能看到，写入期间只读
+	flushingSets     map[string]pendingSet
+	flushingCounters map[string]*pendingCounter
+	// 保证同一时间只有一次写入，直接写入redis前等待正在进行的写入完成，避免旧数据覆盖新数据
+	flushMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+type pendingSet struct {
+	value string
+	exp   time.Duration
+}
+
+type pendingCounter struct {
+	// 开始缓冲时的值，用于计算Incr的返回值
+	base  int64
+	delta int64
+}
+
+// 启用写缓冲，Set、Incr、IncrBy、Decr、DecrBy不再直接写入redis，而是每隔flushInterval通过一个pipeline批量写入
+// 调用Close时会写入剩余的数据；读取时优先返回尚未写入的值，SetNX、CompareAndSwap、Del等直接操作redis的方法会先写入该key缓冲的数据
+// 进程崩溃时尚未写入的数据会丢失，写入失败时只会上报错误不会重试，适合高频更新且允许少量丢失的计数器等场景
+// 计数器第一次缓冲时会从redis读取当前值，Incr返回的是基于该值计算的结果，其他进程同时修改时可能不准确，但写入redis时使用INCRBY不会覆盖其他进程的修改
+// flushInterval不大于0时写入剩余的数据并关闭写缓冲
+func (rad *RadCache) UseWriteBehind(flushInterval time.Duration) {
+	rad.stopWriteBehind()
+	if flushInterval <= 0 {
+		rad.wb = nil
+		return
+	}
+	wb := &writeBehind{
+		sets:     make(map[string]pendingSet),
+		counters: make(map[string]*pendingCounter),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	rad.wb = wb
+	go func() {
+		defer close(wb.done)
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rad.flushWriteBehind(wb)
+			case <-wb.stop:
+				rad.flushWriteBehind(wb)
+				return
+			}
+		}
+	}()
+}
+
+// 停止写缓冲的定时写入并写入剩余的数据，可以重复调用
+func (rad *RadCache) stopWriteBehind() {
+	wb := rad.wb
+	if wb == nil {
+		return
+	}
+	wb.once.Do(func() {
+		close(wb.stop)
+	})
+	<-wb.done
+}
+
+// 直接写入或删除redis中的key(已包含前缀)前需要调用，先写入这些key尚未写入的数据，
+// 条件写入(SetNX、CompareAndSwap等)才能看到缓冲的值，之后的写入也不会被缓冲的旧数据覆盖
+func (rad *RadCache) flushPending(keys ...string) {
+	if rad.wb == nil || len(keys) == 0 {
+		return
+	}
+	rad.flushWriteBehind(rad.wb, keys...)
+}
+
+// 将缓冲的数据通过一个pipeline写入redis，同一个key先SET再INCRBY，指定了only时只写入这些key(已包含前缀)
+func (rad *RadCache) flushWriteBehind(wb *writeBehind, only ...string) {
+	wb.flushMu.Lock()
+	defer wb.flushMu.Unlock()
+	wb.mu.Lock()
+	sets, counters := wb.take(only)
+	if len(sets) == 0 && len(counters) == 0 {
+		wb.mu.Unlock()
+		return
+	}
+	wb.flushingSets, wb.flushingCounters = sets, counters
+	wb.mu.Unlock()
+	defer func() {
+		wb.mu.Lock()
+		wb.flushingSets, wb.flushingCounters = nil, nil
+		wb.mu.Unlock()
+	}()
+	// 后台写入不能使用可能已被取消的rad.Ctx
+	ctx := rad.WithContext(context.Background()).ctx()
+	keys := make([]string, 0, len(sets)+len(counters))
+	_, err := rad.db().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for k, v := range sets {
+			pipe.Set(ctx, k, v.value, v.exp)
+			keys = append(keys, k)
+		}
+		for k, c := range counters {
+			pipe.IncrBy(ctx, k, c.delta)
+			if _, ok := sets[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		return nil
+	})
+	rad.invalidate(keys...)
+	if err != nil {
+		rad.report("WriteBehind", strings.Join(keys, ","), err)
+	}
+}
+
+// 取出需要写入的数据，only为空时取出全部，调用时需要持有mu
+func (wb *writeBehind) take(only []string) (map[string]pendingSet, map[string]*pendingCounter) {
+	if len(only) == 0 {
+		sets, counters := wb.sets, wb.counters
+		wb.sets = make(map[string]pendingSet)
+		wb.counters = make(map[string]*pendingCounter)
+		return sets, counters
+	}
+	sets := make(map[string]pendingSet)
+	counters := make(map[string]*pendingCounter)
+	for _, k := range only {
+		if s, ok := wb.sets[k]; ok {
+			sets[k] = s
+			delete(wb.sets, k)
+		}
+		if c, ok := wb.counters[k]; ok {
+			counters[k] = c
+			delete(wb.counters, k)
+		}
+	}
+	return sets, counters
+}
+
+// 缓冲一次Set，会覆盖同一个key尚未写入的值和计数器增量
+func (wb *writeBehind) set(key, value string, exp time.Duration) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	wb.sets[key] = pendingSet{value: value, exp: exp}
+	delete(wb.counters, key)
+}
+
+// 缓冲一次计数器增量，返回增加后的值
+func (rad *RadCache) bufferIncr(key string, n int64) (int64, error) {
+	wb := rad.wb
+	k := rad.Key(key)
+	wb.mu.Lock()
+	if c, ok := wb.counters[k]; ok {
+		c.delta += n
+		result := c.base + c.delta
+		wb.mu.Unlock()
+		return result, nil
+	}
+	val, buffered := wb.lookup(k)
+	wb.mu.Unlock()
+	var err error
+	if !buffered {
+		val, err = rad.db().Get(rad.ctx(), k).Result()
+		if err == redis.Nil {
+			val, err = "0", nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	base, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	c, ok := wb.counters[k]
+	if !ok {
+		c = &pendingCounter{base: base}
+		wb.counters[k] = c
+	}
+	c.delta += n
+	return c.base + c.delta, nil
+}
+
+// 获取key(已包含前缀)尚未写入redis的值，包括正在写入的数据
+func (wb *writeBehind) get(key string) (string, bool) {
+	if wb == nil {
+		return "", false
+	}
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.lookup(key)
+}
+
+// get的实现，调用时需要持有mu
+func (wb *writeBehind) lookup(key string) (string, bool) {
+	if c, ok := wb.counters[key]; ok {
+		return strconv.FormatInt(c.base+c.delta, 10), true
+	}
+	if s, ok := wb.sets[key]; ok {
+		return s.value, true
+	}
+	if c, ok := wb.flushingCounters[key]; ok {
+		return strconv.FormatInt(c.base+c.delta, 10), true
+	}
+	if s, ok := wb.flushingSets[key]; ok {
+		return s.value, true
+	}
+	return "", false
+}
@@ -0,0 +1,121 @@
+package radcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestMGetReadsBuffered(t *testing.T) {
+	rad := NewDefault()
+	rad.UseLocalCache(16, time.Minute)
+	rad.UseWriteBehind(time.Hour)
+	defer rad.Close()
+	rad.Set("a", "one", 0)
+	rad.local.set(rad.Key("b"), mustMarshal(t, rad, "two"))
+
+	got, err := rad.MGet("a", "b")
+	if err != nil {
+		t.Fatalf("MGet error = %v", err)
+	}
+	if got["a"] != "one" || got["b"] != "two" {
+		t.Fatalf("MGet = %v, want map[a:one b:two]", got)
+	}
+	// 不在写缓冲和本地缓存中的key仍需要读取redis
+	if _, err := rad.MGet("a", "c"); !errors.Is(err, ErrNoClient) {
+		t.Fatalf("MGet error = %v, want ErrNoClient", err)
+	}
+}
+
+func TestWriteBehindLookup(t *testing.T) {
+	tests := []struct {
+		name             string
+		sets             map[string]pendingSet
+		counters         map[string]*pendingCounter
+		flushingSets     map[string]pendingSet
+		flushingCounters map[string]*pendingCounter
+		want             string
+		found            bool
+	}{
+		{name: "empty"},
+		{name: "pending set", sets: map[string]pendingSet{"k": {value: "a"}}, want: "a", found: true},
+		{name: "pending counter", counters: map[string]*pendingCounter{"k": {base: 1, delta: 2}}, want: "3", found: true},
+		{name: "flushing set", flushingSets: map[string]pendingSet{"k": {value: "old"}}, want: "old", found: true},
+		{name: "flushing counter", flushingCounters: map[string]*pendingCounter{"k": {base: 5, delta: -1}}, want: "4", found: true},
+		{
+			name:         "pending wins over flushing",
+			sets:         map[string]pendingSet{"k": {value: "new"}},
+			flushingSets: map[string]pendingSet{"k": {value: "old"}},
+			want:         "new",
+			found:        true,
+		},
+		{
+			name:             "counter wins over set",
+			sets:             map[string]pendingSet{"k": {value: "new"}},
+			counters:         map[string]*pendingCounter{"k": {base: 0, delta: 7}},
+			flushingCounters: map[string]*pendingCounter{"k": {base: 0, delta: 1}},
+			want:             "7",
+			found:            true,
+		},
+		{name: "other key", flushingSets: map[string]pendingSet{"other": {value: "x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wb := &writeBehind{
+				sets:             tt.sets,
+				counters:         tt.counters,
+				flushingSets:     tt.flushingSets,
+				flushingCounters: tt.flushingCounters,
+			}
+			got, found := wb.get("k")
+			if got != tt.want || found != tt.found {
+				t.Fatalf("get = %q, %v, want %q, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestWriteBehindReadDuringFlush(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: hangingServer(t), MaxRetries: -1})
+	defer client.Close()
+	rad := New(Options{OpTimeout: 100 * time.Millisecond})
+	rad.UseRedis(client)
+	rad.UseWriteBehind(time.Hour)
+	defer rad.UseWriteBehind(0)
+	rad.Set("k", "old", 0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rad.flushWriteBehind(rad.wb)
+	}()
+	for {
+		rad.wb.mu.Lock()
+		flushing := rad.wb.flushingSets != nil
+		rad.wb.mu.Unlock()
+		if flushing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// 写入redis期间仍能读到正在写入的值，新缓冲的值优先
+	if got, err := rad.GetString("k"); err != nil || got != mustMarshal(t, rad, "old") {
+		t.Fatalf("GetString during flush = %q, %v", got, err)
+	}
+	rad.Set("k", "new", 0)
+	if got, err := rad.GetString("k"); err != nil || got != mustMarshal(t, rad, "new") {
+		t.Fatalf("GetString after Set during flush = %q, %v", got, err)
+	}
+	<-done
+}
+
+func mustMarshal(t *testing.T, rad *RadCache, v interface{}) string {
+	t.Helper()
+	val, err := rad.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return val
+}
@@ -0,0 +1,68 @@
+package radcache
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// 开启EarlyRefreshBeta时GetOrSet的实现，按XFetch算法决定是否提前调用loader
+// 提前刷新时loader出错会返回仍未过期的旧值；值中记录了重新计算耗时时每次读取会多一次PTTL请求
+func (rad *RadCache) getOrSetEarly(key string, exp time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	result, stale, err := rad.xfetch(key)
+	if err == nil && !stale {
+		return result, nil
+	}
+	if err != nil && (!errors.Is(err, ErrCacheMiss) || errors.Is(err, ErrNotFound)) {
+		return nil, err
+	}
+	val, loadErr := rad.load(key, exp, loader)
+	if loadErr != nil && err == nil && !errors.Is(loadErr, ErrNotFound) {
+		return result, nil
+	}
+	return val, loadErr
+}
+
+// 通过fetch获取值(与Get相同，会经过写缓冲、本地缓存、重试和fallback)，值中记录了重新计算耗时时再通过PTTL判断是否需要提前刷新
+// 获取剩余过期时间失败时不提前刷新
+func (rad *RadCache) xfetch(key string) (interface{}, bool, error) {
+	val, err := rad.fetch("GetOrSet", key)
+	if err != nil {
+		rad.report("GetOrSet", key, err)
+		return nil, false, err
+	}
+	data, delta, err := rad.unpackDelta([]byte(val))
+	var result interface{}
+	if err == nil {
+		err = rad.serializer().Unmarshal(data, &result)
+	}
+	if err != nil {
+		rad.dropStale(key, err)
+		rad.report("GetOrSet", key, err)
+		return nil, false, err
+	}
+	if delta <= 0 {
+		return result, false, nil
+	}
+	var ttl time.Duration
+	err = rad.retry(func() (err error) {
+		ttl, err = rad.db().PTTL(rad.ctx(), rad.Key(key)).Result()
+		return err
+	})
+	if err != nil {
+		rad.report("GetOrSet", key, err)
+		return result, false, nil
+	}
+	return result, rad.expiresEarly(delta, ttl), nil
+}
+
+// XFetch算法：-delta * beta * ln(rand) >= 剩余过期时间时提前刷新，越接近过期概率越大
+// 没有记录耗时或没有设置过期时间的值不会提前刷新
+func (rad *RadCache) expiresEarly(delta, ttl time.Duration) bool {
+	if delta <= 0 || ttl <= 0 {
+		return false
+	}
+	gap := -float64(delta) * rad.Options.EarlyRefreshBeta * math.Log(1-rand.Float64())
+	return gap >= float64(ttl)
+}
@@ -0,0 +1,33 @@
+package radcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiresEarly(t *testing.T) {
+	tests := []struct {
+		name  string
+		beta  float64
+		delta time.Duration
+		ttl   time.Duration
+		want  bool
+	}{
+		{"no delta", 1e9, 0, time.Millisecond, false},
+		{"no expiration", 1e9, time.Second, TTLNoExpire, false},
+		{"key missing", 1e9, time.Second, TTLNotExist, false},
+		{"far from expiring", 1, time.Millisecond, time.Hour, false},
+		{"about to expire", 1e9, time.Second, time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rad := NewDefault()
+			rad.Options.EarlyRefreshBeta = tt.beta
+			for i := 0; i < 100; i++ {
+				if got := rad.expiresEarly(tt.delta, tt.ttl); got != tt.want {
+					t.Fatalf("expiresEarly(%v, %v) = %v, want %v", tt.delta, tt.ttl, got, tt.want)
+				}
+			}
+		})
+	}
+}
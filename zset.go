@@ -0,0 +1,74 @@
+package radcache
+
+import "github.com/go-redis/redis/v8"
+
+// 有序集合中的成员及其分数
+type ZMember struct {
+	Member interface{}
+	Score  float64
+}
+
+// 向有序集合中添加成员，成员已存在时更新其分数
+func (rad *RadCache) ZAdd(key string, score float64, member interface{}) error {
+	val, err := rad.marshalMember(member)
+	if err != nil {
+		rad.report("ZAdd", key, err)
+		return err
+	}
+	err = rad.db().ZAdd(rad.ctx(), rad.Key(key), &redis.Z{Score: score, Member: val}).Err()
+	if err != nil {
+		rad.report("ZAdd", key, err)
+	}
+	return err
+}
+
+// 按分数从低到高获取指定排名范围内的成员
+func (rad *RadCache) ZRange(key string, start, stop int64) ([]interface{}, error) {
+	values, err := rad.db().ZRange(rad.ctx(), rad.Key(key), start, stop).Result()
+	if err != nil {
+		rad.report("ZRange", key, err)
+		return nil, err
+	}
+	result, err := rad.unmarshalAll(values)
+	if err != nil {
+		rad.report("ZRange", key, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// 按分数从低到高获取指定排名范围内的成员及其分数
+func (rad *RadCache) ZRangeWithScores(key string, start, stop int64) ([]ZMember, error) {
+	values, err := rad.db().ZRangeWithScores(rad.ctx(), rad.Key(key), start, stop).Result()
+	if err != nil {
+		rad.report("ZRangeWithScores", key, err)
+		return nil, err
+	}
+	result := make([]ZMember, 0, len(values))
+	for _, z := range values {
+		str, _ := z.Member.(string)
+		member, err := rad.UnMarshal(str)
+		if err != nil {
+			rad.report("ZRangeWithScores", key, err)
+			return nil, err
+		}
+		result = append(result, ZMember{Member: member, Score: z.Score})
+	}
+	return result, nil
+}
+
+// 获取成员的分数，成员不存在时返回ErrCacheMiss
+func (rad *RadCache) ZScore(key string, member interface{}) (float64, error) {
+	val, err := rad.marshalMember(member)
+	if err != nil {
+		rad.report("ZScore", key, err)
+		return 0.0, err
+	}
+	result, err := rad.db().ZScore(rad.ctx(), rad.Key(key), val).Result()
+	if err != nil {
+		err = missErr(err)
+		rad.report("ZScore", key, err)
+		return 0.0, err
+	}
+	return result, nil
+}